@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -37,6 +39,50 @@ func retryWithContext(ctx context.Context, targetFunc retryableFunc, delay time.
 	}
 }
 
+// runConcurrently runs a function for the indices 0 to n-1 concurrently and
+// returns the errors indexed the same way.
+func runConcurrently(n int, targetFunc func(i int) error) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = targetFunc(i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// waitForResource polls a resource with get until done returns true for it, and returns the last polled resource.
+func waitForResource[T any](ctx context.Context, delay time.Duration, get func() (T, error), done func(T) bool) (T, error) {
+	var resource T
+	err := retryWithContext(ctx, func() (bool, error) {
+		var err error
+		resource, err = get()
+		if err != nil {
+			return false, err
+		}
+		return !done(resource), nil
+	}, delay)
+	return resource, err
+}
+
+// joinErrors joins the non-nil errors into a single error, or returns nil if there is none.
+func joinErrors(errs []error) error {
+	var messages []string
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
 // retryNTimes reruns a function within a number of retries.
 func retryNTimes(targetFunc retryableFunc, numOfRetries int, delay time.Duration) error {
 	retryNo := 0
@@ -117,3 +117,38 @@ func Test_retryNTimes(t *testing.T) {
 		}
 	}
 }
+
+func Test_runConcurrently(t *testing.T) {
+	errs := runConcurrently(3, func(i int) error {
+		if i == 1 {
+			return errors.New("just test")
+		}
+		return nil
+	})
+	assert.Equal(t, []error{nil, errors.New("just test"), nil}, errs)
+}
+
+func Test_joinErrors(t *testing.T) {
+	assert.Nil(t, joinErrors(nil))
+	assert.Nil(t, joinErrors([]error{nil, nil}))
+	assert.Equal(t, "a; b", joinErrors([]error{errors.New("a"), nil, errors.New("b")}).Error())
+}
+
+func Test_waitForResource(t *testing.T) {
+	polls := 0
+	resource, err := waitForResource(emptyCtx, time.Millisecond, func() (int, error) {
+		polls++
+		return polls, nil
+	}, func(n int) bool {
+		return n == 3
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, resource)
+
+	_, err = waitForResource(emptyCtx, time.Millisecond, func() (int, error) {
+		return 0, errors.New("just test")
+	}, func(n int) bool {
+		return true
+	})
+	assert.NotNil(t, err)
+}
@@ -18,6 +18,12 @@ type IPOperator interface {
 	GetIPVersion(ctx context.Context, id string) int
 	GetIPsByLocation(ctx context.Context, id string) ([]IP, error)
 	GetDeletedIPs(ctx context.Context) ([]IP, error)
+	MoveIP(ctx context.Context, ipID, fromServerID, toServerID string) error
+	GetIPPool(ctx context.Context, label string) ([]IP, error)
+	AllocateIPFromPool(ctx context.Context, label string, family IPAddressType) (IP, error)
+	ReleaseIPToPool(ctx context.Context, id string) error
+	GetUnusedIPs(ctx context.Context) ([]IP, error)
+	SyncReverseDNS(ctx context.Context, body ReverseDNSSyncRequest) (ReverseDNSSyncReport, error)
 }
 
 // IPList holds a list of IP addresses.
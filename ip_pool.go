@@ -0,0 +1,179 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// IPPoolAllocatedLabel is the label marking an IP address of a pool as allocated.
+const IPPoolAllocatedLabel = "ip-pool-allocated"
+
+// MoveIP hands an IP address (e.g. a failover IP) over from one server to another.
+// The IP address is unlinked from the old server, linked to the new one, and the new relation
+// is verified. If linking or verifying fails, the IP address is linked back to the old server.
+func (c *Client) MoveIP(ctx context.Context, ipID, fromServerID, toServerID string) error {
+	if !isValidUUID(ipID) || !isValidUUID(fromServerID) || !isValidUUID(toServerID) {
+		return errors.New("'ipID', 'fromServerID' or 'toServerID' is invalid")
+	}
+	ip, err := c.GetIP(ctx, ipID)
+	if err != nil {
+		return err
+	}
+	if !isIPRelatedToServer(ip, fromServerID) {
+		return fmt.Errorf("IP %s is not linked to server %s", ip.Properties.IP, fromServerID)
+	}
+	err = c.UnlinkIP(ctx, fromServerID, ipID)
+	if err != nil {
+		return err
+	}
+	err = c.LinkIP(ctx, toServerID, ipID)
+	var unlinkErr error
+	if err == nil {
+		err = c.verifyIPRelatedToServer(ctx, ipID, toServerID)
+		if err != nil {
+			// Make sure the IP address is not linked to both servers during the rollback.
+			unlinkErr = c.UnlinkIP(ctx, toServerID, ipID)
+			if unlinkErr != nil {
+				unlinkErr = fmt.Errorf("unlinking IP %s from server %s failed: %v", ip.Properties.IP, toServerID, unlinkErr)
+			}
+		}
+	}
+	if err != nil {
+		errs := []error{fmt.Errorf("moving IP %s failed: %v", ip.Properties.IP, err), unlinkErr}
+		rollbackErr := c.LinkIP(ctx, fromServerID, ipID)
+		if rollbackErr != nil {
+			errs = append(errs, fmt.Errorf("rollback failed: %v", rollbackErr))
+		}
+		return joinErrors(errs)
+	}
+	return nil
+}
+
+// verifyIPRelatedToServer checks if an IP address is linked to a server.
+func (c *Client) verifyIPRelatedToServer(ctx context.Context, ipID, serverID string) error {
+	ip, err := c.GetIP(ctx, ipID)
+	if err != nil {
+		return err
+	}
+	if !isIPRelatedToServer(ip, serverID) {
+		return fmt.Errorf("IP %s is not linked to server %s", ip.Properties.IP, serverID)
+	}
+	return nil
+}
+
+// isIPRelatedToServer checks if an IP address has a relation to a server.
+func isIPRelatedToServer(ip IP, serverID string) bool {
+	for _, server := range ip.Properties.Relations.Servers {
+		if server.ServerUUID == serverID {
+			return true
+		}
+	}
+	return false
+}
+
+// isIPUnused checks if an IP address is neither related to a server nor to a load balancer.
+func isIPUnused(ip IP) bool {
+	return len(ip.Properties.Relations.Servers) == 0 && len(ip.Properties.Relations.Loadbalancers) == 0
+}
+
+// GetIPPool returns the IP addresses of a pool, i.e. all IP addresses having the pool's label.
+func (c *Client) GetIPPool(ctx context.Context, label string) ([]IP, error) {
+	if label == "" {
+		return nil, errors.New("'label' is empty")
+	}
+	ips, err := c.GetIPList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pool []IP
+	for _, ip := range ips {
+		if hasLabel(ip.Properties.Labels, label) {
+			pool = append(pool, ip)
+		}
+	}
+	return pool, nil
+}
+
+// AllocateIPFromPool allocates an IP address of a given family from a pool. An unused and not yet
+// allocated IP address of the pool is reused if there is any, otherwise a new IP address is created.
+// Allocated IP addresses are marked with the IPPoolAllocatedLabel label.
+func (c *Client) AllocateIPFromPool(ctx context.Context, label string, family IPAddressType) (IP, error) {
+	if family != IPv4Type && family != IPv6Type {
+		return IP{}, errors.New("'family' is invalid")
+	}
+	pool, err := c.GetIPPool(ctx, label)
+	if err != nil {
+		return IP{}, err
+	}
+	for _, ip := range pool {
+		if ip.Properties.Family != int(family) || !isIPUnused(ip) || hasLabel(ip.Properties.Labels, IPPoolAllocatedLabel) {
+			continue
+		}
+		labels := append(append(make([]string, 0), ip.Properties.Labels...), IPPoolAllocatedLabel)
+		err = c.UpdateIP(ctx, ip.Properties.ObjectUUID, IPUpdateRequest{
+			Failover: ip.Properties.Failover,
+			Labels:   &labels,
+		})
+		if err != nil {
+			return IP{}, err
+		}
+		return c.GetIP(ctx, ip.Properties.ObjectUUID)
+	}
+	created, err := c.CreateIP(ctx, IPCreateRequest{
+		Family: family,
+		Labels: []string{label, IPPoolAllocatedLabel},
+	})
+	if err != nil {
+		return IP{}, err
+	}
+	return c.GetIP(ctx, created.ObjectUUID)
+}
+
+// ReleaseIPToPool gives an allocated IP address back to its pool, so that it can be allocated again.
+// The IP address must not be in use anymore.
+func (c *Client) ReleaseIPToPool(ctx context.Context, id string) error {
+	ip, err := c.GetIP(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isIPUnused(ip) {
+		return fmt.Errorf("IP %s is still in use", ip.Properties.IP)
+	}
+	labels := make([]string, 0)
+	for _, label := range ip.Properties.Labels {
+		if label != IPPoolAllocatedLabel {
+			labels = append(labels, label)
+		}
+	}
+	return c.UpdateIP(ctx, id, IPUpdateRequest{
+		Failover: ip.Properties.Failover,
+		Labels:   &labels,
+	})
+}
+
+// GetUnusedIPs returns the IP addresses which are neither related to a server nor to a load balancer,
+// but are still billed.
+func (c *Client) GetUnusedIPs(ctx context.Context) ([]IP, error) {
+	ips, err := c.GetIPList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var unused []IP
+	for _, ip := range ips {
+		if isIPUnused(ip) {
+			unused = append(unused, ip)
+		}
+	}
+	return unused, nil
+}
+
+// hasLabel checks if a list of labels contains a given label.
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
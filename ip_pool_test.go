@@ -0,0 +1,206 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_MoveIP(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	toServerUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	var linkedServers []string
+	var isVerifyFailed, isRollbackFailed bool
+	mux.HandleFunc(path.Join(apiIPBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		ip := getMockIP("active")
+		for _, serverID := range linkedServers {
+			if isVerifyFailed && serverID == toServerUUID {
+				continue
+			}
+			ip.Properties.Relations.Servers = append(ip.Properties.Relations.Servers, IPServer{ServerUUID: serverID})
+		}
+		res, _ := json.Marshal(ip)
+		fmt.Fprint(w, string(res))
+	})
+	for _, serverID := range []string{dummyUUID, toServerUUID} {
+		serverID := serverID
+		mux.HandleFunc(path.Join(apiServerBase, serverID, "ips"), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			if isRollbackFailed && serverID == dummyUUID {
+				w.WriteHeader(400)
+				return
+			}
+			linkedServers = append(linkedServers, serverID)
+		})
+		mux.HandleFunc(path.Join(apiServerBase, serverID, "ips", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			var remaining []string
+			for _, id := range linkedServers {
+				if id != serverID {
+					remaining = append(remaining, id)
+				}
+			}
+			linkedServers = remaining
+		})
+	}
+	testCases := []struct {
+		isVerifyFailed   bool
+		isRollbackFailed bool
+		expectedServers  []string
+	}{
+		{
+			expectedServers: []string{toServerUUID},
+		},
+		{
+			isVerifyFailed:  true,
+			expectedServers: []string{dummyUUID},
+		},
+		{
+			isVerifyFailed:   true,
+			isRollbackFailed: true,
+			expectedServers:  nil,
+		},
+	}
+	for _, test := range testCases {
+		linkedServers = []string{dummyUUID}
+		isVerifyFailed = test.isVerifyFailed
+		isRollbackFailed = test.isRollbackFailed
+		err := client.MoveIP(emptyCtx, dummyUUID, dummyUUID, toServerUUID)
+		if test.isVerifyFailed {
+			if assert.NotNil(t, err) {
+				assert.Equal(t, test.isRollbackFailed, strings.Contains(err.Error(), "rollback failed"))
+			}
+		} else {
+			assert.Nil(t, err, "MoveIP returned an error %v", err)
+		}
+		assert.Equal(t, test.expectedServers, linkedServers)
+	}
+
+	isVerifyFailed, isRollbackFailed = false, false
+	linkedServers = []string{toServerUUID}
+	err := client.MoveIP(emptyCtx, dummyUUID, dummyUUID, toServerUUID)
+	assert.NotNil(t, err)
+	err = client.MoveIP(emptyCtx, dummyUUID, "", toServerUUID)
+	assert.NotNil(t, err)
+}
+
+func TestClient_AllocateIPFromPool(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	createdUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	var isPoolExhausted bool
+	var updated IPUpdateRequest
+	var created IPCreateRequest
+	mux.HandleFunc(apiIPBase, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, createdUUID, dummyRequestUUID)
+			return
+		}
+		ip := getMockIP("active")
+		ip.Properties.Family = int(IPv4Type)
+		ip.Properties.Labels = []string{"pool"}
+		ip.Properties.Relations = IPRelations{}
+		if isPoolExhausted {
+			ip.Properties.Labels = append(ip.Properties.Labels, IPPoolAllocatedLabel)
+		}
+		res, _ := json.Marshal(ip.Properties)
+		fmt.Fprintf(w, `{"ips": {"%s": %s}}`, dummyUUID, string(res))
+	})
+	mux.HandleFunc(path.Join(apiIPBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodPatch {
+			json.NewDecoder(r.Body).Decode(&updated)
+			return
+		}
+		fmt.Fprint(w, prepareIPHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiIPBase, createdUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		ip := getMockIP("active")
+		ip.Properties.ObjectUUID = createdUUID
+		res, _ := json.Marshal(ip)
+		fmt.Fprint(w, string(res))
+	})
+
+	ip, err := client.AllocateIPFromPool(emptyCtx, "pool", IPv4Type)
+	assert.Nil(t, err, "AllocateIPFromPool returned an error %v", err)
+	assert.Equal(t, dummyUUID, ip.Properties.ObjectUUID)
+	assert.Equal(t, []string{"pool", IPPoolAllocatedLabel}, *updated.Labels)
+
+	isPoolExhausted = true
+	ip, err = client.AllocateIPFromPool(emptyCtx, "pool", IPv4Type)
+	assert.Nil(t, err, "AllocateIPFromPool returned an error %v", err)
+	assert.Equal(t, createdUUID, ip.Properties.ObjectUUID)
+	assert.Equal(t, IPv4Type, created.Family)
+	assert.Equal(t, []string{"pool", IPPoolAllocatedLabel}, created.Labels)
+
+	_, err = client.AllocateIPFromPool(emptyCtx, "", IPv4Type)
+	assert.NotNil(t, err)
+	_, err = client.AllocateIPFromPool(emptyCtx, "pool", 0)
+	assert.NotNil(t, err)
+}
+
+func TestClient_ReleaseIPToPool(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var isInUse bool
+	var updated IPUpdateRequest
+	mux.HandleFunc(path.Join(apiIPBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodPatch {
+			json.NewDecoder(r.Body).Decode(&updated)
+			return
+		}
+		ip := getMockIP("active")
+		ip.Properties.Labels = []string{"pool", IPPoolAllocatedLabel}
+		if !isInUse {
+			ip.Properties.Relations = IPRelations{}
+		}
+		res, _ := json.Marshal(ip)
+		fmt.Fprint(w, string(res))
+	})
+	err := client.ReleaseIPToPool(emptyCtx, dummyUUID)
+	assert.Nil(t, err, "ReleaseIPToPool returned an error %v", err)
+	assert.Equal(t, []string{"pool"}, *updated.Labels)
+
+	isInUse = true
+	err = client.ReleaseIPToPool(emptyCtx, dummyUUID)
+	assert.NotNil(t, err)
+}
+
+func TestClient_GetUnusedIPs(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	unusedUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	mux.HandleFunc(apiIPBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		used := getMockIP("active")
+		unused := getMockIP("active")
+		unused.Properties.ObjectUUID = unusedUUID
+		unused.Properties.Relations = IPRelations{}
+		list := IPList{List: map[string]IPProperties{
+			dummyUUID:  used.Properties,
+			unusedUUID: unused.Properties,
+		}}
+		res, _ := json.Marshal(list)
+		fmt.Fprint(w, string(res))
+	})
+	ips, err := client.GetUnusedIPs(emptyCtx)
+	assert.Nil(t, err, "GetUnusedIPs returned an error %v", err)
+	assert.Equal(t, 1, len(ips))
+	assert.Equal(t, unusedUUID, ips[0].Properties.ObjectUUID)
+}
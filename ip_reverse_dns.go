@@ -0,0 +1,221 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ReverseDNSIPPlaceholder is replaced by the IP address (with dots and colons replaced by dashes)
+// in PTR records of a ReverseDNSSyncRequest, e.g. "mail-{ip}.example.com".
+const ReverseDNSIPPlaceholder = "{ip}"
+
+// ReverseDNSResolver resolves host names and IP addresses. It is used to verify forward-confirmed
+// reverse DNS (FCrDNS). *net.Resolver implements this interface.
+type ReverseDNSResolver interface {
+	// LookupAddr returns the host names of an IP address.
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+
+	// LookupHost returns the IP addresses of a host name.
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// ReverseDNSSyncRequest represents a request for setting the reverse DNS entries of many IP addresses at once.
+type ReverseDNSSyncRequest struct {
+	// Desired PTR records. A key is either an IP address, in any notation, or a glob pattern (see path.Match)
+	// matched against the names of the IP addresses. Exact IP addresses take precedence over patterns.
+	// A value may contain ReverseDNSIPPlaceholder.
+	Records map[string]string
+
+	// Resolver used to verify forward-confirmed reverse DNS after applying the records.
+	// Leave it nil to skip the verification.
+	Resolver ReverseDNSResolver
+}
+
+// ReverseDNSSyncReport represents the result of syncing reverse DNS entries.
+type ReverseDNSSyncReport struct {
+	// Changed reverse DNS entries.
+	Changes []ReverseDNSChange
+
+	// Number of matched IP addresses whose reverse DNS entry was already correct.
+	Unchanged int
+
+	// IP addresses failing the forward-confirmed reverse DNS verification.
+	VerificationFailures []ReverseDNSVerificationFailure
+}
+
+// ReverseDNSChange represents a changed reverse DNS entry.
+type ReverseDNSChange struct {
+	// The UUID of the IP address.
+	IPUUID string
+
+	// The IP address.
+	IP string
+
+	// The reverse DNS entry before the change.
+	OldReverseDNS string
+
+	// The reverse DNS entry after the change.
+	NewReverseDNS string
+}
+
+// ReverseDNSVerificationFailure represents an IP address failing the forward-confirmed reverse DNS verification.
+type ReverseDNSVerificationFailure struct {
+	// The IP address.
+	IP string
+
+	// The expected PTR record.
+	ReverseDNS string
+
+	// The reason of the failure.
+	Reason string
+}
+
+// SyncReverseDNS sets the reverse DNS entries of the IP addresses matched by the request's records.
+// Only entries differing from the desired ones are updated. If a resolver is given, all matched
+// IP addresses are verified afterwards, the failures are listed in the report.
+func (c *Client) SyncReverseDNS(ctx context.Context, body ReverseDNSSyncRequest) (ReverseDNSSyncReport, error) {
+	var report ReverseDNSSyncReport
+	if len(body.Records) == 0 {
+		return report, errors.New("'Records' is empty")
+	}
+	// IP addresses are looked up in their canonical form, e.g. lowercase and compressed for IPv6.
+	records := make(map[string]string, len(body.Records))
+	for key, value := range body.Records {
+		if addr := net.ParseIP(key); addr != nil {
+			if _, ok := records[addr.String()]; ok {
+				return report, fmt.Errorf("IP %s is duplicated", key)
+			}
+			records[addr.String()] = value
+			continue
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return report, fmt.Errorf("pattern %s is invalid: %v", key, err)
+		}
+		records[key] = value
+	}
+	ips, err := c.GetIPList(ctx)
+	if err != nil {
+		return report, err
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		return ips[i].Properties.IP < ips[j].Properties.IP
+	})
+	var matched []IP
+	var ptrs []string
+	for _, ip := range ips {
+		ptr, ok, err := desiredReverseDNS(ip.Properties, records)
+		if err != nil {
+			return report, err
+		}
+		if !ok {
+			continue
+		}
+		matched = append(matched, ip)
+		ptrs = append(ptrs, ptr)
+		if strings.EqualFold(strings.TrimSuffix(ip.Properties.ReverseDNS, "."), strings.TrimSuffix(ptr, ".")) {
+			report.Unchanged++
+			continue
+		}
+		err = c.UpdateIP(ctx, ip.Properties.ObjectUUID, IPUpdateRequest{
+			Failover:   ip.Properties.Failover,
+			ReverseDNS: ptr,
+		})
+		if err != nil {
+			return report, fmt.Errorf("updating reverse DNS of IP %s failed: %v", ip.Properties.IP, err)
+		}
+		report.Changes = append(report.Changes, ReverseDNSChange{
+			IPUUID:        ip.Properties.ObjectUUID,
+			IP:            ip.Properties.IP,
+			OldReverseDNS: ip.Properties.ReverseDNS,
+			NewReverseDNS: ptr,
+		})
+	}
+	if body.Resolver == nil {
+		return report, nil
+	}
+	for i, ip := range matched {
+		err = VerifyReverseDNS(ctx, body.Resolver, ip.Properties.IP, ptrs[i])
+		if err != nil {
+			report.VerificationFailures = append(report.VerificationFailures, ReverseDNSVerificationFailure{
+				IP:         ip.Properties.IP,
+				ReverseDNS: ptrs[i],
+				Reason:     err.Error(),
+			})
+		}
+	}
+	return report, nil
+}
+
+// VerifyReverseDNS checks forward-confirmed reverse DNS of an IP address: the IP address must
+// resolve to the given PTR record, and the PTR record must resolve back to the IP address.
+func VerifyReverseDNS(ctx context.Context, resolver ReverseDNSResolver, ip, ptr string) error {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return fmt.Errorf("IP %s is invalid", ip)
+	}
+	names, err := resolver.LookupAddr(ctx, ip)
+	if err != nil {
+		return fmt.Errorf("reverse lookup of %s failed: %v", ip, err)
+	}
+	ptr = strings.TrimSuffix(ptr, ".")
+	found := false
+	for _, name := range names {
+		if strings.EqualFold(strings.TrimSuffix(name, "."), ptr) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s resolves to %v instead of %s", ip, names, ptr)
+	}
+	addrs, err := resolver.LookupHost(ctx, ptr)
+	if err != nil {
+		return fmt.Errorf("forward lookup of %s failed: %v", ptr, err)
+	}
+	for _, a := range addrs {
+		if addr.Equal(net.ParseIP(a)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s resolves to %v instead of %s", ptr, addrs, ip)
+}
+
+// desiredReverseDNS returns the desired PTR record of an IP address, and whether the IP address
+// is matched by the records at all. IP address keys of the records must be canonical.
+func desiredReverseDNS(ip IPProperties, records map[string]string) (string, bool, error) {
+	key := ip.IP
+	if addr := net.ParseIP(ip.IP); addr != nil {
+		key = addr.String()
+	}
+	ptr, ok := records[key]
+	if !ok {
+		var matchedPatterns []string
+		for key, value := range records {
+			if net.ParseIP(key) != nil {
+				continue
+			}
+			if match, _ := path.Match(key, ip.Name); match && ip.Name != "" {
+				matchedPatterns = append(matchedPatterns, key)
+				ptr = value
+			}
+		}
+		if len(matchedPatterns) == 0 {
+			return "", false, nil
+		}
+		if len(matchedPatterns) > 1 {
+			sort.Strings(matchedPatterns)
+			return "", false, fmt.Errorf("IP %s is matched by several patterns %v", ip.IP, matchedPatterns)
+		}
+	}
+	replacer := strings.NewReplacer(".", "-", ":", "-")
+	ptr = strings.ReplaceAll(ptr, ReverseDNSIPPlaceholder, replacer.Replace(ip.IP))
+	if ptr == "" {
+		return "", false, fmt.Errorf("PTR record of IP %s is empty", ip.IP)
+	}
+	return ptr, true, nil
+}
@@ -0,0 +1,154 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReverseDNSResolver struct {
+	addrs map[string][]string
+	hosts map[string][]string
+}
+
+func (r mockReverseDNSResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	names, ok := r.addrs[addr]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return names, nil
+}
+
+func (r mockReverseDNSResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestClient_SyncReverseDNS(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	secondUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	thirdUUID := "4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d"
+	updates := make(map[string]IPUpdateRequest)
+	mux.HandleFunc(apiIPBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		first := getMockIP("active")
+		first.Properties.Name = "mail-1"
+		first.Properties.ReverseDNS = "mail-192-168-0-1.example.com."
+		second := getMockIP("active")
+		second.Properties.ObjectUUID = secondUUID
+		second.Properties.Name = "web"
+		second.Properties.IP = "192.168.0.2"
+		second.Properties.Failover = true
+		third := getMockIP("active")
+		third.Properties.ObjectUUID = thirdUUID
+		third.Properties.IP = "2001:db8::1"
+		third.Properties.ReverseDNS = "v6.example.com."
+		list := IPList{List: map[string]IPProperties{
+			dummyUUID:  first.Properties,
+			secondUUID: second.Properties,
+			thirdUUID:  third.Properties,
+		}}
+		res, _ := json.Marshal(list)
+		fmt.Fprint(w, string(res))
+	})
+	for _, id := range []string{dummyUUID, secondUUID, thirdUUID} {
+		id := id
+		mux.HandleFunc(path.Join(apiIPBase, id), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			var body IPUpdateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			updates[id] = body
+		})
+	}
+	resolver := mockReverseDNSResolver{
+		addrs: map[string][]string{
+			"192.168.0.1": {"mail-192-168-0-1.example.com."},
+			"192.168.0.2": {"www.example.com."},
+			"2001:db8::1": {"v6.example.com."},
+		},
+		hosts: map[string][]string{
+			"mail-192-168-0-1.example.com": {"192.168.0.1"},
+			"v6.example.com":               {"2001:db8::1"},
+		},
+	}
+	report, err := client.SyncReverseDNS(emptyCtx, ReverseDNSSyncRequest{
+		Records: map[string]string{
+			"mail-*":      "mail-{ip}.example.com",
+			"192.168.0.2": "web.example.com",
+			// A non-canonical IPv6 address matches the canonical one of the API.
+			"2001:DB8:0:0:0:0:0:1": "v6.example.com",
+		},
+		Resolver: resolver,
+	})
+	assert.Nil(t, err, "SyncReverseDNS returned an error %v", err)
+	assert.Equal(t, 2, report.Unchanged)
+	assert.Equal(t, []ReverseDNSChange{
+		{
+			IPUUID:        secondUUID,
+			IP:            "192.168.0.2",
+			OldReverseDNS: "8.8.8.8",
+			NewReverseDNS: "web.example.com",
+		},
+	}, report.Changes)
+	assert.Equal(t, IPUpdateRequest{Failover: true, ReverseDNS: "web.example.com"}, updates[secondUUID])
+	assert.Equal(t, 1, len(updates))
+	assert.Equal(t, 1, len(report.VerificationFailures))
+	assert.Equal(t, "192.168.0.2", report.VerificationFailures[0].IP)
+
+	_, err = client.SyncReverseDNS(emptyCtx, ReverseDNSSyncRequest{
+		Records: map[string]string{"2001:db8::1": "a.example.com", "2001:DB8:0:0:0:0:0:1": "b.example.com"},
+	})
+	assert.NotNil(t, err)
+	_, err = client.SyncReverseDNS(emptyCtx, ReverseDNSSyncRequest{
+		Records: map[string]string{"mail-*": "a.example.com", "mail-?": "b.example.com"},
+	})
+	assert.NotNil(t, err)
+	_, err = client.SyncReverseDNS(emptyCtx, ReverseDNSSyncRequest{Records: map[string]string{"[": "a.example.com"}})
+	assert.NotNil(t, err)
+	_, err = client.SyncReverseDNS(emptyCtx, ReverseDNSSyncRequest{})
+	assert.NotNil(t, err)
+}
+
+func TestVerifyReverseDNS(t *testing.T) {
+	resolver := mockReverseDNSResolver{
+		addrs: map[string][]string{
+			"192.168.0.1": {"mail.example.com."},
+			"2001:db8::1": {"MAIL6.example.com."},
+		},
+		hosts: map[string][]string{
+			"mail.example.com":  {"192.168.0.2"},
+			"mail6.example.com": {"2001:db8:0:0::1"},
+		},
+	}
+	testCases := []struct {
+		ip       string
+		ptr      string
+		isFailed bool
+	}{
+		{ip: "2001:db8::1", ptr: "mail6.example.com.", isFailed: false},
+		{ip: "192.168.0.1", ptr: "mail.example.com", isFailed: true},
+		{ip: "192.168.0.1", ptr: "other.example.com", isFailed: true},
+		{ip: "192.168.0.3", ptr: "mail.example.com", isFailed: true},
+		{ip: "invalid", ptr: "mail.example.com", isFailed: true},
+	}
+	for _, test := range testCases {
+		err := VerifyReverseDNS(emptyCtx, resolver, test.ip, test.ptr)
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "VerifyReverseDNS returned an error %v", err)
+		}
+	}
+}
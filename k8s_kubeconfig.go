@@ -0,0 +1,339 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default values of K8sCredentialRenewOptions.
+const (
+	defaultK8sRenewBefore   = time.Hour
+	defaultK8sRetryInterval = time.Minute
+)
+
+// Kubeconfig represents a kubeconfig file. Only the names of clusters, contexts and users are typed,
+// all other fields are kept as they are.
+type Kubeconfig struct {
+	// Version of the kubeconfig format, usually "v1".
+	APIVersion string `yaml:"apiVersion"`
+
+	// Kind of the file, always "Config".
+	Kind string `yaml:"kind"`
+
+	// Name of the context used by default.
+	CurrentContext string `yaml:"current-context"`
+
+	// List of clusters.
+	Clusters []KubeconfigNamedItem `yaml:"clusters"`
+
+	// List of contexts.
+	Contexts []KubeconfigNamedItem `yaml:"contexts"`
+
+	// List of users.
+	Users []KubeconfigNamedItem `yaml:"users"`
+
+	// Other top-level fields, e.g. preferences.
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// KubeconfigNamedItem represents a named cluster, context or user of a kubeconfig.
+type KubeconfigNamedItem struct {
+	// Name of the item.
+	Name string `yaml:"name"`
+
+	// Fields of the item, e.g. "cluster", "context" or "user".
+	Fields map[string]interface{} `yaml:",inline"`
+}
+
+// K8sKubeconfig represents the kubeconfig of a Kubernetes PaaS service.
+type K8sKubeconfig struct {
+	// The parsed kubeconfig.
+	Config Kubeconfig
+
+	// The kubeconfig as returned by the API.
+	Raw string
+
+	// Expiration time of the credentials.
+	ExpirationTime GSTime
+}
+
+// K8sCredentialRenewOptions represents the options of RunK8sCredentialRenewer.
+type K8sCredentialRenewOptions struct {
+	// The credentials are renewed RenewBefore their expiration. Leave it 0 to use 1 hour.
+	RenewBefore time.Duration
+
+	// Delay after a failed attempt. Leave it 0 to use 1 minute.
+	RetryInterval time.Duration
+
+	// If set, the kubeconfig is merged into this file at the start and after each renewal, see MergeKubeconfigFile.
+	KubeconfigPath string
+
+	// Name of the context in the kubeconfig file. Required if KubeconfigPath is set.
+	ContextName string
+
+	// Called with the refreshed kubeconfig after each renewal. Optional.
+	OnRenew func(kubeconfig K8sKubeconfig)
+
+	// Called with errors, which are retried after RetryInterval. Optional.
+	OnError func(err error)
+}
+
+// ParseKubeconfig parses a kubeconfig and checks that it contains clusters, contexts and users.
+func ParseKubeconfig(data []byte) (Kubeconfig, error) {
+	var config Kubeconfig
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+	if len(config.Clusters) == 0 || len(config.Contexts) == 0 || len(config.Users) == 0 {
+		return config, errors.New("invalid kubeconfig: clusters, contexts and users are required")
+	}
+	return config, nil
+}
+
+// Marshal returns the kubeconfig as YAML.
+func (k Kubeconfig) Marshal() ([]byte, error) {
+	return yaml.Marshal(k)
+}
+
+// GetK8sKubeconfig returns the kubeconfig of a Kubernetes PaaS service, which is part of its credentials.
+func (c *Client) GetK8sKubeconfig(ctx context.Context, id string) (K8sKubeconfig, error) {
+	service, err := c.GetPaaSService(ctx, id)
+	if err != nil {
+		return K8sKubeconfig{}, err
+	}
+	for _, credential := range service.Properties.Credentials {
+		if credential.KubeConfig == "" {
+			continue
+		}
+		config, err := ParseKubeconfig([]byte(credential.KubeConfig))
+		if err != nil {
+			return K8sKubeconfig{}, err
+		}
+		return K8sKubeconfig{
+			Config:         config,
+			Raw:            credential.KubeConfig,
+			ExpirationTime: credential.ExpirationTime,
+		}, nil
+	}
+	return K8sKubeconfig{}, fmt.Errorf("PaaS service %s has no kubeconfig", id)
+}
+
+// MergeKubeconfig merges the current context of src, with its cluster and user, into dst. The context,
+// cluster and user are renamed to contextName, replacing existing entries with this name.
+// If setCurrent is true, the context becomes the current context of dst.
+func MergeKubeconfig(dst, src Kubeconfig, contextName string, setCurrent bool) (Kubeconfig, error) {
+	if contextName == "" {
+		return dst, errors.New("'contextName' is required")
+	}
+	srcContextName := src.CurrentContext
+	if srcContextName == "" && len(src.Contexts) > 0 {
+		srcContextName = src.Contexts[0].Name
+	}
+	srcContext, ok := findKubeconfigItem(src.Contexts, srcContextName)
+	if !ok {
+		return dst, fmt.Errorf("context %s not found in kubeconfig", srcContextName)
+	}
+	contextFields, _ := srcContext.Fields["context"].(map[string]interface{})
+	clusterName, _ := contextFields["cluster"].(string)
+	userName, _ := contextFields["user"].(string)
+	cluster, ok := findKubeconfigItem(src.Clusters, clusterName)
+	if !ok {
+		return dst, fmt.Errorf("cluster %s not found in kubeconfig", clusterName)
+	}
+	user, ok := findKubeconfigItem(src.Users, userName)
+	if !ok {
+		return dst, fmt.Errorf("user %s not found in kubeconfig", userName)
+	}
+
+	renamedContext := make(map[string]interface{}, len(contextFields))
+	for key, value := range contextFields {
+		renamedContext[key] = value
+	}
+	renamedContext["cluster"] = contextName
+	renamedContext["user"] = contextName
+	// Copy the lists to leave the lists of the caller unchanged.
+	dst.Clusters = append([]KubeconfigNamedItem(nil), dst.Clusters...)
+	dst.Users = append([]KubeconfigNamedItem(nil), dst.Users...)
+	dst.Contexts = append([]KubeconfigNamedItem(nil), dst.Contexts...)
+	dst.Clusters = replaceKubeconfigItem(dst.Clusters, KubeconfigNamedItem{Name: contextName, Fields: cluster.Fields})
+	dst.Users = replaceKubeconfigItem(dst.Users, KubeconfigNamedItem{Name: contextName, Fields: user.Fields})
+	dst.Contexts = replaceKubeconfigItem(dst.Contexts, KubeconfigNamedItem{
+		Name:   contextName,
+		Fields: map[string]interface{}{"context": renamedContext},
+	})
+	if dst.APIVersion == "" {
+		dst.APIVersion = "v1"
+	}
+	if dst.Kind == "" {
+		dst.Kind = "Config"
+	}
+	if setCurrent || dst.CurrentContext == "" {
+		dst.CurrentContext = contextName
+	}
+	return dst, nil
+}
+
+// MergeKubeconfigFile merges a kubeconfig into a local kubeconfig file under a named context, see MergeKubeconfig.
+// The file is created if it does not exist, and replaced atomically otherwise.
+func MergeKubeconfigFile(path string, config Kubeconfig, contextName string, setCurrent bool) error {
+	var dst Kubeconfig
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && len(data) > 0 {
+		err = yaml.Unmarshal(data, &dst)
+		if err != nil {
+			return fmt.Errorf("invalid kubeconfig %s: %v", path, err)
+		}
+	}
+	merged, err := MergeKubeconfig(dst, config, contextName, setCurrent)
+	if err != nil {
+		return err
+	}
+	data, err = merged.Marshal()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// RunK8sCredentialRenewer keeps the credentials of a Kubernetes PaaS service valid by calling RenewK8sCredentials
+// before they expire, and emits the refreshed kubeconfig via opts.OnRenew and opts.KubeconfigPath.
+// It blocks until the context is done and returns the context's error, so run it in a goroutine.
+func (c *Client) RunK8sCredentialRenewer(ctx context.Context, id string, opts K8sCredentialRenewOptions) error {
+	if opts.RenewBefore < 0 || opts.RetryInterval < 0 {
+		return errors.New("'RenewBefore' and 'RetryInterval' must not be negative")
+	}
+	if opts.KubeconfigPath != "" && opts.ContextName == "" {
+		return errors.New("'ContextName' is required if 'KubeconfigPath' is set")
+	}
+	if opts.RenewBefore == 0 {
+		opts.RenewBefore = defaultK8sRenewBefore
+	}
+	if opts.RetryInterval == 0 {
+		opts.RetryInterval = defaultK8sRetryInterval
+	}
+	onError := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+	}
+
+	// fetch retries until it gets a kubeconfig with a known expiration time.
+	fetch := func() (K8sKubeconfig, error) {
+		for {
+			kubeconfig, err := c.emitK8sKubeconfig(ctx, id, opts)
+			if err == nil {
+				return kubeconfig, nil
+			}
+			onError(err)
+			if !sleepWithContext(ctx, opts.RetryInterval) {
+				return kubeconfig, ctx.Err()
+			}
+		}
+	}
+
+	kubeconfig, err := fetch()
+	if err != nil {
+		return err
+	}
+	var minWait time.Duration
+	for {
+		wait := time.Until(kubeconfig.ExpirationTime.Add(-opts.RenewBefore))
+		if wait < minWait {
+			wait = minWait
+		}
+		if !sleepWithContext(ctx, wait) {
+			return ctx.Err()
+		}
+		// Avoid a tight loop after failures, or if the renewed credentials expire soon, too.
+		minWait = opts.RetryInterval
+		err := c.RenewK8sCredentials(ctx, id)
+		if err != nil {
+			onError(fmt.Errorf("renewing k8s credentials of %s failed: %v", id, err))
+			continue
+		}
+		kubeconfig, err = fetch()
+		if err != nil {
+			return err
+		}
+		if opts.OnRenew != nil {
+			opts.OnRenew(kubeconfig)
+		}
+	}
+}
+
+// emitK8sKubeconfig gets the kubeconfig of a Kubernetes PaaS service and merges it into opts.KubeconfigPath, if set.
+// A kubeconfig without expiration time is written, but returned with an error, because it cannot be renewed in time.
+func (c *Client) emitK8sKubeconfig(ctx context.Context, id string, opts K8sCredentialRenewOptions) (K8sKubeconfig, error) {
+	kubeconfig, err := c.GetK8sKubeconfig(ctx, id)
+	if err != nil {
+		return kubeconfig, err
+	}
+	if opts.KubeconfigPath != "" {
+		err = MergeKubeconfigFile(opts.KubeconfigPath, kubeconfig.Config, opts.ContextName, false)
+		if err != nil {
+			return kubeconfig, fmt.Errorf("writing kubeconfig %s failed: %v", opts.KubeconfigPath, err)
+		}
+	}
+	if kubeconfig.ExpirationTime.IsZero() {
+		return kubeconfig, fmt.Errorf("k8s credentials of %s have no expiration time", id)
+	}
+	return kubeconfig, nil
+}
+
+// findKubeconfigItem returns the item with the given name.
+func findKubeconfigItem(items []KubeconfigNamedItem, name string) (KubeconfigNamedItem, bool) {
+	for _, item := range items {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return KubeconfigNamedItem{}, false
+}
+
+// replaceKubeconfigItem replaces the item with the same name, or appends the item if there is none.
+func replaceKubeconfigItem(items []KubeconfigNamedItem, item KubeconfigNamedItem) []KubeconfigNamedItem {
+	for i := range items {
+		if items[i].Name == item.Name {
+			items[i] = item
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// sleepWithContext waits for the duration and returns false if the context is done before.
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,244 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMockKubeconfig(token string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: admin@cluster
+clusters:
+- name: cluster
+  cluster:
+    server: https://192.0.2.10:6443
+    certificate-authority-data: Y2E=
+contexts:
+- name: admin@cluster
+  context:
+    cluster: cluster
+    user: admin
+    namespace: default
+users:
+- name: admin
+  user:
+    token: %s
+`, token)
+}
+
+func TestParseKubeconfig(t *testing.T) {
+	config, err := ParseKubeconfig([]byte(getMockKubeconfig("secret")))
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+	assert.Equal(t, "admin@cluster", config.CurrentContext)
+	assert.Equal(t, "cluster", config.Clusters[0].Name)
+	assert.Equal(t, map[string]interface{}{"token": "secret"}, config.Users[0].Fields["user"])
+
+	_, err = ParseKubeconfig([]byte("apiVersion: v1\nkind: Config\n"))
+	assert.NotNil(t, err)
+	_, err = ParseKubeconfig([]byte("clusters: ["))
+	assert.NotNil(t, err)
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	src, err := ParseKubeconfig([]byte(getMockKubeconfig("secret")))
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+	dst, err := ParseKubeconfig([]byte(`apiVersion: v1
+kind: Config
+current-context: local
+preferences: {}
+clusters:
+- name: local
+  cluster:
+    server: https://127.0.0.1:6443
+- name: gsk
+  cluster:
+    server: https://192.0.2.99:6443
+contexts:
+- name: local
+  context:
+    cluster: local
+    user: local
+users:
+- name: local
+  user:
+    token: local
+`))
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+
+	merged, err := MergeKubeconfig(dst, src, "gsk", false)
+	assert.Nil(t, err, "MergeKubeconfig returned an error %v", err)
+	assert.Equal(t, "local", merged.CurrentContext)
+	assert.Equal(t, map[string]interface{}{"preferences": map[string]interface{}{}}, merged.Extra)
+	assert.Equal(t, 2, len(merged.Clusters))
+	assert.Equal(t, src.Clusters[0].Fields, merged.Clusters[1].Fields)
+	assert.Equal(t, "https://192.0.2.99:6443", dst.Clusters[1].Fields["cluster"].(map[string]interface{})["server"])
+	assert.Equal(t, KubeconfigNamedItem{
+		Name: "gsk",
+		Fields: map[string]interface{}{"context": map[string]interface{}{
+			"cluster":   "gsk",
+			"user":      "gsk",
+			"namespace": "default",
+		}},
+	}, merged.Contexts[1])
+	assert.Equal(t, "gsk", merged.Users[1].Name)
+
+	merged, err = MergeKubeconfig(Kubeconfig{}, src, "gsk", false)
+	assert.Nil(t, err, "MergeKubeconfig returned an error %v", err)
+	assert.Equal(t, "gsk", merged.CurrentContext)
+	assert.Equal(t, "v1", merged.APIVersion)
+	assert.Equal(t, "Config", merged.Kind)
+
+	_, err = MergeKubeconfig(dst, src, "", true)
+	assert.NotNil(t, err)
+	src.CurrentContext = "missing"
+	_, err = MergeKubeconfig(dst, src, "gsk", true)
+	assert.NotNil(t, err)
+}
+
+func TestMergeKubeconfigFile(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	src, err := ParseKubeconfig([]byte(getMockKubeconfig("first")))
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+	err = MergeKubeconfigFile(kubeconfigPath, src, "gsk", true)
+	assert.Nil(t, err, "MergeKubeconfigFile returned an error %v", err)
+
+	src, err = ParseKubeconfig([]byte(getMockKubeconfig("second")))
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+	err = MergeKubeconfigFile(kubeconfigPath, src, "gsk", true)
+	assert.Nil(t, err, "MergeKubeconfigFile returned an error %v", err)
+
+	info, err := os.Stat(kubeconfigPath)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := os.ReadFile(kubeconfigPath)
+	assert.Nil(t, err)
+	config, err := ParseKubeconfig(data)
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+	assert.Equal(t, 1, len(config.Users))
+	assert.Equal(t, map[string]interface{}{"token": "second"}, config.Users[0].Fields["user"])
+
+	err = os.WriteFile(kubeconfigPath, []byte("clusters: ["), 0600)
+	assert.Nil(t, err)
+	assert.NotNil(t, MergeKubeconfigFile(kubeconfigPath, src, "gsk", true))
+}
+
+func TestClient_GetK8sKubeconfig(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	expiration := GSTime{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	credentials := []Credential{{Username: "admin", Type: "postgres"}}
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(PaaSService{Properties: PaaSServiceProperties{ObjectUUID: dummyUUID, Credentials: credentials}})
+		fmt.Fprint(w, string(res))
+	})
+	_, err := client.GetK8sKubeconfig(emptyCtx, dummyUUID)
+	assert.NotNil(t, err)
+
+	credentials = append(credentials, Credential{Type: "kubeconfig", KubeConfig: getMockKubeconfig("secret"), ExpirationTime: expiration})
+	kubeconfig, err := client.GetK8sKubeconfig(emptyCtx, dummyUUID)
+	assert.Nil(t, err, "GetK8sKubeconfig returned an error %v", err)
+	assert.Equal(t, getMockKubeconfig("secret"), kubeconfig.Raw)
+	assert.Equal(t, expiration.Unix(), kubeconfig.ExpirationTime.Unix())
+	assert.Equal(t, "admin@cluster", kubeconfig.Config.CurrentContext)
+}
+
+func TestClient_RunK8sCredentialRenewer(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	renewals := 0
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		// The first credentials expire soon, the renewed ones later.
+		expiration := time.Now().Add(30 * time.Minute)
+		if renewals > 0 {
+			expiration = time.Now().Add(72 * time.Hour)
+		}
+		res, _ := json.Marshal(PaaSService{Properties: PaaSServiceProperties{
+			ObjectUUID: dummyUUID,
+			Credentials: []Credential{{
+				KubeConfig:     getMockKubeconfig(fmt.Sprintf("token-%d", renewals)),
+				ExpirationTime: GSTime{expiration},
+			}},
+		}})
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID, "renew_credentials"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		renewals++
+		fmt.Fprint(w, "")
+	})
+
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	ctx, cancel := context.WithTimeout(emptyCtx, 10*time.Second)
+	defer cancel()
+	var renewed []K8sKubeconfig
+	err := client.RunK8sCredentialRenewer(ctx, dummyUUID, K8sCredentialRenewOptions{
+		KubeconfigPath: kubeconfigPath,
+		ContextName:    "gsk",
+		OnRenew: func(kubeconfig K8sKubeconfig) {
+			renewed = append(renewed, kubeconfig)
+			cancel()
+		},
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, renewals)
+	if assert.Equal(t, 1, len(renewed)) {
+		assert.True(t, renewed[0].ExpirationTime.After(time.Now().Add(71*time.Hour)))
+	}
+	data, err := os.ReadFile(kubeconfigPath)
+	assert.Nil(t, err)
+	config, err := ParseKubeconfig(data)
+	assert.Nil(t, err, "ParseKubeconfig returned an error %v", err)
+	assert.Equal(t, map[string]interface{}{"token": "token-1"}, config.Users[0].Fields["user"])
+
+	err = client.RunK8sCredentialRenewer(emptyCtx, dummyUUID, K8sCredentialRenewOptions{KubeconfigPath: kubeconfigPath})
+	assert.NotNil(t, err)
+}
+
+func TestClient_RunK8sCredentialRenewer_withoutExpirationTime(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	renewals := 0
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(PaaSService{Properties: PaaSServiceProperties{
+			ObjectUUID:  dummyUUID,
+			Credentials: []Credential{{KubeConfig: getMockKubeconfig("token")}},
+		}})
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID, "renew_credentials"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		renewals++
+		fmt.Fprint(w, "")
+	})
+
+	ctx, cancel := context.WithTimeout(emptyCtx, 10*time.Second)
+	defer cancel()
+	var errs []error
+	err := client.RunK8sCredentialRenewer(ctx, dummyUUID, K8sCredentialRenewOptions{
+		RetryInterval: 10 * time.Millisecond,
+		OnError: func(err error) {
+			errs = append(errs, err)
+			if len(errs) == 3 {
+				cancel()
+			}
+		},
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 3, len(errs))
+	assert.Equal(t, 0, renewals)
+}
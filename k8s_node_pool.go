@@ -0,0 +1,158 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// K8sDefaultNodePool is the name of the worker node pool of a GSK cluster.
+// GSK templates configure the worker nodes of a cluster by a single set of parameters, so this is the only pool.
+const K8sDefaultNodePool = "default"
+
+// Parameters of a GSK template configuring the worker nodes.
+const (
+	k8sWorkerNodeCountParameter       = "k8s_worker_node_count"
+	k8sWorkerNodeCoresParameter       = "k8s_worker_node_cores"
+	k8sWorkerNodeRAMParameter         = "k8s_worker_node_ram"
+	k8sWorkerNodeStorageParameter     = "k8s_worker_node_storage"
+	k8sWorkerNodeStorageTypeParameter = "k8s_worker_node_storage_type"
+)
+
+// K8sNodePool represents a pool of worker nodes of a GSK cluster.
+type K8sNodePool struct {
+	// Name of the pool.
+	Name string
+
+	// Number of worker nodes.
+	NodeCount int
+
+	// Number of cores per worker node.
+	Cores int
+
+	// Memory per worker node (in GB).
+	Memory int
+
+	// Storage per worker node (in GB).
+	Storage int
+
+	// Storage type of the worker nodes, e.g. storage, storage_high or storage_insane.
+	StorageType string
+}
+
+// K8sNodePoolUpdateRequest represents a request for updating a pool of worker nodes of a GSK cluster.
+// Leave a field nil if you do not want to update it.
+type K8sNodePoolUpdateRequest struct {
+	// Number of worker nodes.
+	NodeCount *int
+
+	// Number of cores per worker node.
+	Cores *int
+
+	// Memory per worker node (in GB).
+	Memory *int
+
+	// Storage per worker node (in GB).
+	Storage *int
+
+	// Storage type of the worker nodes.
+	StorageType *string
+}
+
+// GetK8sNodePools returns the pools of worker nodes of a GSK cluster.
+func (c *Client) GetK8sNodePools(ctx context.Context, id string) ([]K8sNodePool, error) {
+	service, err := c.GetPaaSService(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := k8sNodePoolFromParameters(service.Properties.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("PaaS service %s: %v", id, err)
+	}
+	return []K8sNodePool{pool}, nil
+}
+
+// ScaleK8sNodePool changes the number of worker nodes of a pool of a GSK cluster, see UpdateK8sNodePool.
+func (c *Client) ScaleK8sNodePool(ctx context.Context, id, poolName string, nodeCount int) error {
+	return c.UpdateK8sNodePool(ctx, id, poolName, K8sNodePoolUpdateRequest{NodeCount: &nodeCount})
+}
+
+// UpdateK8sNodePool updates a pool of worker nodes of a GSK cluster. The changes are validated against
+// the parameters schema of the cluster's template before the update, and the method waits until
+// the cluster is active again.
+func (c *Client) UpdateK8sNodePool(ctx context.Context, id, poolName string, body K8sNodePoolUpdateRequest) error {
+	if poolName != K8sDefaultNodePool {
+		return fmt.Errorf("node pool %s not found", poolName)
+	}
+	params := k8sNodePoolParameters(body)
+	if len(params) == 0 {
+		return errors.New("no node pool changes given")
+	}
+	service, err := c.GetPaaSService(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := service.Properties.Parameters[k8sWorkerNodeCountParameter]; !ok {
+		return fmt.Errorf("PaaS service %s is not a GSK cluster", id)
+	}
+	template, err := c.findPaaSTemplate(ctx, service.Properties.ServiceTemplateUUID)
+	if err != nil {
+		return err
+	}
+	err = ValidatePaaSParameterUpdate(template.ParametersSchema, service.Properties.Parameters, params)
+	if err != nil {
+		return err
+	}
+	_, err = c.waitForPaaSServiceActive(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = c.UpdatePaaSService(ctx, id, PaaSServiceUpdateRequest{Parameters: params})
+	if err != nil {
+		return err
+	}
+	_, err = c.waitForPaaSServiceActive(ctx, id)
+	return err
+}
+
+// k8sNodePoolFromParameters reads the worker node pool from the parameters of a GSK cluster.
+func k8sNodePoolFromParameters(params map[string]interface{}) (K8sNodePool, error) {
+	pool := K8sNodePool{Name: K8sDefaultNodePool}
+	count, ok := paasParameterNumber(params[k8sWorkerNodeCountParameter])
+	if !ok {
+		return pool, errors.New("not a GSK cluster")
+	}
+	pool.NodeCount = int(count)
+	if cores, ok := paasParameterNumber(params[k8sWorkerNodeCoresParameter]); ok {
+		pool.Cores = int(cores)
+	}
+	if memory, ok := paasParameterNumber(params[k8sWorkerNodeRAMParameter]); ok {
+		pool.Memory = int(memory)
+	}
+	if storage, ok := paasParameterNumber(params[k8sWorkerNodeStorageParameter]); ok {
+		pool.Storage = int(storage)
+	}
+	pool.StorageType, _ = params[k8sWorkerNodeStorageTypeParameter].(string)
+	return pool, nil
+}
+
+// k8sNodePoolParameters translates a node pool update to PaaS service parameters.
+func k8sNodePoolParameters(body K8sNodePoolUpdateRequest) map[string]interface{} {
+	params := make(map[string]interface{})
+	if body.NodeCount != nil {
+		params[k8sWorkerNodeCountParameter] = *body.NodeCount
+	}
+	if body.Cores != nil {
+		params[k8sWorkerNodeCoresParameter] = *body.Cores
+	}
+	if body.Memory != nil {
+		params[k8sWorkerNodeRAMParameter] = *body.Memory
+	}
+	if body.Storage != nil {
+		params[k8sWorkerNodeStorageParameter] = *body.Storage
+	}
+	if body.StorageType != nil {
+		params[k8sWorkerNodeStorageTypeParameter] = *body.StorageType
+	}
+	return params
+}
@@ -0,0 +1,109 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetK8sNodePools(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	params := getMockK8sParameters()
+	prepareK8sNodePoolHTTP(t, mux, params, nil)
+	pools, err := client.GetK8sNodePools(emptyCtx, dummyUUID)
+	assert.Nil(t, err, "GetK8sNodePools returned an error %v", err)
+	assert.Equal(t, []K8sNodePool{{
+		Name:        K8sDefaultNodePool,
+		NodeCount:   3,
+		Cores:       2,
+		Memory:      4,
+		Storage:     30,
+		StorageType: "storage_insane",
+	}}, pools)
+
+	delete(params, k8sWorkerNodeCountParameter)
+	_, err = client.GetK8sNodePools(emptyCtx, dummyUUID)
+	assert.NotNil(t, err)
+}
+
+func TestClient_UpdateK8sNodePool(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var updates []map[string]interface{}
+	prepareK8sNodePoolHTTP(t, mux, getMockK8sParameters(), &updates)
+
+	err := client.ScaleK8sNodePool(emptyCtx, dummyUUID, K8sDefaultNodePool, 5)
+	assert.Nil(t, err, "ScaleK8sNodePool returned an error %v", err)
+	cores, storageType := 4, "storage_high"
+	err = client.UpdateK8sNodePool(emptyCtx, dummyUUID, K8sDefaultNodePool, K8sNodePoolUpdateRequest{Cores: &cores, StorageType: &storageType})
+	assert.Nil(t, err, "UpdateK8sNodePool returned an error %v", err)
+	assert.Equal(t, []map[string]interface{}{
+		{k8sWorkerNodeCountParameter: float64(5)},
+		{k8sWorkerNodeCoresParameter: float64(4), k8sWorkerNodeStorageTypeParameter: "storage_high"},
+	}, updates)
+
+	err = client.ScaleK8sNodePool(emptyCtx, dummyUUID, K8sDefaultNodePool, 50)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "parameter 'k8s_worker_node_count' is invalid: must be between 1 and 10", err.Error())
+	}
+	storageType = "storage_fast"
+	err = client.UpdateK8sNodePool(emptyCtx, dummyUUID, K8sDefaultNodePool, K8sNodePoolUpdateRequest{StorageType: &storageType})
+	assert.NotNil(t, err)
+	err = client.ScaleK8sNodePool(emptyCtx, dummyUUID, "pool-1", 5)
+	assert.NotNil(t, err)
+	err = client.UpdateK8sNodePool(emptyCtx, dummyUUID, K8sDefaultNodePool, K8sNodePoolUpdateRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(updates))
+}
+
+func getMockK8sParameters() map[string]interface{} {
+	return map[string]interface{}{
+		k8sWorkerNodeCountParameter:       3,
+		k8sWorkerNodeCoresParameter:       2,
+		k8sWorkerNodeRAMParameter:         4,
+		k8sWorkerNodeStorageParameter:     30,
+		k8sWorkerNodeStorageTypeParameter: "storage_insane",
+	}
+}
+
+func prepareK8sNodePoolHTTP(t *testing.T, mux *http.ServeMux, params map[string]interface{}, updates *[]map[string]interface{}) {
+	mux.HandleFunc(path.Join(apiPaaSBase, "service_templates"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(PaaSTemplates{List: map[string]PaaSTemplateProperties{
+			dummyUUID: {
+				ObjectUUID: dummyUUID,
+				Name:       "GSK 1.26",
+				ParametersSchema: map[string]Parameter{
+					k8sWorkerNodeCountParameter:       {Type: "integer", Min: 1, Max: 10},
+					k8sWorkerNodeCoresParameter:       {Type: "integer", Min: 1, Max: 32},
+					k8sWorkerNodeRAMParameter:         {Type: "integer", Min: 2, Max: 256},
+					k8sWorkerNodeStorageParameter:     {Type: "integer", Min: 30, Max: 1024},
+					k8sWorkerNodeStorageTypeParameter: {Type: "string", Allowed: []string{"storage", "storage_high", "storage_insane"}},
+				},
+			},
+		}})
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		switch r.Method {
+		case http.MethodGet:
+			res, _ := json.Marshal(PaaSService{Properties: PaaSServiceProperties{
+				ObjectUUID:          dummyUUID,
+				ServiceTemplateUUID: dummyUUID,
+				Status:              resourceActiveStatus,
+				Parameters:          params,
+			}})
+			fmt.Fprint(w, string(res))
+		case http.MethodPatch:
+			var body PaaSServiceUpdateRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+			*updates = append(*updates, body.Parameters)
+		}
+	})
+}
@@ -15,6 +15,7 @@ type LoadBalancerOperator interface {
 	UpdateLoadBalancer(ctx context.Context, id string, body LoadBalancerUpdateRequest) error
 	DeleteLoadBalancer(ctx context.Context, id string) error
 	GetLoadBalancerEventList(ctx context.Context, id string) ([]Event, error)
+	RotateLoadBalancerBackends(ctx context.Context, id string, body LoadBalancerBackendRotationRequest) error
 }
 
 // LoadBalancers holds a list of load balancers.
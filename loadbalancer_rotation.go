@@ -0,0 +1,183 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LoadBalancerHealthProbe checks whether a load balancer serves traffic correctly.
+// It is called after every step of a backend rotation, a non-nil error aborts
+// the rotation and rolls the load balancer back to its original backend servers.
+type LoadBalancerHealthProbe func(ctx context.Context, loadBalancer LoadBalancer) error
+
+// LoadBalancerBackendRotationRequest represents a request for shifting the traffic of a load balancer
+// from one pool of backend servers (blue) to another one (green).
+type LoadBalancerBackendRotationRequest struct {
+	// Backend servers currently receiving the traffic. They are removed from the load balancer
+	// once the rotation is finished. Leave it empty to rotate all current backend servers out.
+	FromBackendServers []BackendServer
+
+	// Backend servers which will receive the traffic. Required.
+	ToBackendServers []BackendServer
+
+	// Percentages of the traffic sent to the new backend servers in each step (e.g. 10, 50, 100).
+	// Values must be increasing and the last one must be 100.
+	// Leave it empty to swap both pools of backend servers atomically.
+	Steps []int
+
+	// Delay between two steps. Optional.
+	StepInterval time.Duration
+
+	// Health probe called after each step. Optional.
+	HealthProbe LoadBalancerHealthProbe
+}
+
+// RotateLoadBalancerBackends shifts the traffic of a load balancer from one pool of backend servers
+// to another one by stepping the backend servers' weights through `body.Steps`.
+// Backend servers which are in neither pool are kept untouched.
+//
+// If a step or the health probe fails, the backend servers of the load balancer are restored.
+func (c *Client) RotateLoadBalancerBackends(ctx context.Context, id string, body LoadBalancerBackendRotationRequest) error {
+	if !isValidUUID(id) {
+		return errors.New("'id' is invalid")
+	}
+	if len(body.ToBackendServers) == 0 {
+		return errors.New("'ToBackendServers' is empty")
+	}
+	steps := body.Steps
+	if len(steps) == 0 {
+		steps = []int{100}
+	}
+	if err := validateRotationSteps(steps); err != nil {
+		return err
+	}
+	lb, err := c.GetLoadBalancer(ctx, id)
+	if err != nil {
+		return err
+	}
+	originalBackends := lb.Properties.BackendServers
+	fromBackends := body.FromBackendServers
+	if len(fromBackends) == 0 {
+		fromBackends = originalBackends
+	}
+
+	for i, percent := range steps {
+		if i > 0 && body.StepInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return c.rollbackLoadBalancerBackends(ctx, lb.Properties, ctx.Err())
+			case <-time.After(body.StepInterval):
+			}
+		}
+		backends := rotatedBackendServers(originalBackends, fromBackends, body.ToBackendServers, percent)
+		err = c.UpdateLoadBalancer(ctx, id, newLoadBalancerUpdateRequest(lb.Properties, backends))
+		if err != nil {
+			return c.rollbackLoadBalancerBackends(ctx, lb.Properties, fmt.Errorf("rotation step %d%% failed: %v", percent, err))
+		}
+		updatedLB, err := c.waitForLoadBalancerActive(ctx, id)
+		if err != nil {
+			return c.rollbackLoadBalancerBackends(ctx, lb.Properties, fmt.Errorf("rotation step %d%% failed: %v", percent, err))
+		}
+		if body.HealthProbe != nil {
+			err = body.HealthProbe(ctx, updatedLB)
+			if err != nil {
+				return c.rollbackLoadBalancerBackends(ctx, lb.Properties, fmt.Errorf("health probe failed at rotation step %d%%: %v", percent, err))
+			}
+		}
+	}
+	return nil
+}
+
+// rollbackLoadBalancerBackends restores the backend servers of a load balancer
+// and returns the error which caused the rollback.
+func (c *Client) rollbackLoadBalancerBackends(ctx context.Context, original LoadBalancerProperties, cause error) error {
+	// The rollback has to run even if the given context is already done.
+	if ctx.Err() != nil {
+		ctx = context.Background()
+	}
+	err := c.UpdateLoadBalancer(ctx, original.ObjectUUID, newLoadBalancerUpdateRequest(original, original.BackendServers))
+	if err != nil {
+		return fmt.Errorf("%v; rollback failed: %v", cause, err)
+	}
+	return cause
+}
+
+// waitForLoadBalancerActive waits until a load balancer is active and returns it.
+func (c *Client) waitForLoadBalancerActive(ctx context.Context, id string) (LoadBalancer, error) {
+	return waitForResource(ctx, c.DelayInterval(), func() (LoadBalancer, error) {
+		return c.GetLoadBalancer(ctx, id)
+	}, func(lb LoadBalancer) bool {
+		return lb.Properties.Status == resourceActiveStatus
+	})
+}
+
+// newLoadBalancerUpdateRequest creates an update request which keeps all the properties
+// of a load balancer, except its backend servers.
+func newLoadBalancerUpdateRequest(properties LoadBalancerProperties, backends []BackendServer) LoadBalancerUpdateRequest {
+	return LoadBalancerUpdateRequest{
+		Name:                properties.Name,
+		ListenIPv6UUID:      properties.ListenIPv6UUID,
+		ListenIPv4UUID:      properties.ListenIPv4UUID,
+		Algorithm:           LoadbalancerAlgorithm(properties.Algorithm),
+		ForwardingRules:     properties.ForwardingRules,
+		BackendServers:      backends,
+		Labels:              properties.Labels,
+		RedirectHTTPToHTTPS: properties.RedirectHTTPToHTTPS,
+	}
+}
+
+// rotatedBackendServers computes the backend servers of a load balancer when `percent` percents
+// of the traffic are sent to the `to` pool. Backend servers being in neither pool are kept.
+func rotatedBackendServers(current, from, to []BackendServer, percent int) []BackendServer {
+	inPools := make(map[string]bool)
+	for _, backend := range from {
+		inPools[backend.Host] = true
+	}
+	for _, backend := range to {
+		inPools[backend.Host] = true
+	}
+	backends := make([]BackendServer, 0)
+	for _, backend := range current {
+		if !inPools[backend.Host] {
+			backends = append(backends, backend)
+		}
+	}
+	if percent < 100 {
+		for _, backend := range from {
+			backend.Weight = scaleBackendWeight(backend.Weight, 100-percent)
+			backends = append(backends, backend)
+		}
+	}
+	for _, backend := range to {
+		backend.Weight = scaleBackendWeight(backend.Weight, percent)
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// scaleBackendWeight scales a backend server's weight to a percentage of it.
+// The result is never less than 1, so that the backend server stays in use.
+func scaleBackendWeight(weight, percent int) int {
+	scaled := weight * percent / 100
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
+
+// validateRotationSteps checks if the steps of a rotation are increasing and end at 100 percent.
+func validateRotationSteps(steps []int) error {
+	previous := 0
+	for _, step := range steps {
+		if step <= previous || step > 100 {
+			return errors.New("'Steps' must be increasing values between 1 and 100")
+		}
+		previous = step
+	}
+	if previous != 100 {
+		return errors.New("the last value of 'Steps' must be 100")
+	}
+	return nil
+}
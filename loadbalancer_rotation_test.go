@@ -0,0 +1,116 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RotateLoadBalancerBackends(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var updates []LoadBalancerUpdateRequest
+	uri := path.Join(apiLoadBalancerBase, dummyUUID)
+	mux.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodPatch {
+			var body LoadBalancerUpdateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			updates = append(updates, body)
+			fmt.Fprintf(w, "")
+		} else if r.Method == http.MethodGet {
+			fmt.Fprint(w, prepareLoadBalancerHTTPGetResponse("active"))
+		}
+	})
+	green := []BackendServer{{Weight: 100, Host: "185.201.147.177"}}
+	testCases := []struct {
+		steps          []int
+		failAtStep     int
+		expectedWeight [][]int
+		isFailed       bool
+	}{
+		{
+			steps:          nil,
+			expectedWeight: [][]int{{100}},
+		},
+		{
+			steps:          []int{10, 50, 100},
+			expectedWeight: [][]int{{90, 10}, {50, 50}, {100}},
+		},
+		{
+			steps:          []int{10, 50, 100},
+			failAtStep:     2,
+			expectedWeight: [][]int{{90, 10}, {50, 50}, {100}},
+			isFailed:       true,
+		},
+		{
+			steps:    []int{50, 10, 100},
+			isFailed: true,
+		},
+		{
+			steps:    []int{10, 50},
+			isFailed: true,
+		},
+	}
+	for _, test := range testCases {
+		updates = nil
+		stepNo := 0
+		failAtStep := test.failAtStep
+		err := client.RotateLoadBalancerBackends(emptyCtx, dummyUUID, LoadBalancerBackendRotationRequest{
+			ToBackendServers: green,
+			Steps:            test.steps,
+			HealthProbe: func(ctx context.Context, lb LoadBalancer) error {
+				stepNo++
+				if stepNo == failAtStep {
+					return errors.New("unhealthy")
+				}
+				return nil
+			},
+		})
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "RotateLoadBalancerBackends returned an error %v", err)
+		}
+		if test.failAtStep > 0 {
+			// The steps up to the failed one plus the rollback.
+			assert.Equal(t, test.failAtStep+1, len(updates))
+			rollback := updates[len(updates)-1]
+			assert.Equal(t, getMockLoadbalancer("active").Properties.BackendServers, rollback.BackendServers)
+			continue
+		}
+		assert.Equal(t, len(test.expectedWeight), len(updates))
+		for i, update := range updates {
+			var weights []int
+			for _, backend := range update.BackendServers {
+				weights = append(weights, backend.Weight)
+			}
+			assert.Equal(t, test.expectedWeight[i], weights)
+			assert.Equal(t, "go-client-lb", update.Name)
+		}
+	}
+}
+
+func TestRotatedBackendServers(t *testing.T) {
+	current := []BackendServer{
+		{Weight: 100, Host: "10.0.0.1"},
+		{Weight: 20, Host: "10.0.0.9"},
+	}
+	from := []BackendServer{{Weight: 100, Host: "10.0.0.1"}}
+	to := []BackendServer{{Weight: 200, Host: "10.0.0.2"}}
+	assert.Equal(t, []BackendServer{
+		{Weight: 20, Host: "10.0.0.9"},
+		{Weight: 99, Host: "10.0.0.1"},
+		{Weight: 2, Host: "10.0.0.2"},
+	}, rotatedBackendServers(current, from, to, 1))
+	assert.Equal(t, []BackendServer{
+		{Weight: 20, Host: "10.0.0.9"},
+		{Weight: 200, Host: "10.0.0.2"},
+	}, rotatedBackendServers(current, from, to, 100))
+}
@@ -22,6 +22,8 @@ type NetworkOperator interface {
 	GetPinnedServerList(ctx context.Context, networkUUID string) (PinnedServerList, error)
 	UpdateNetworkPinnedServer(ctx context.Context, networkUUID, serverUUID string, body PinServerRequest) error
 	DeleteNetworkPinnedServer(ctx context.Context, networkUUID, serverUUID string) error
+	GetNetworkAddressReport(ctx context.Context, networkUUID string) (NetworkAddressReport, error)
+	PinNextFreeNetworkIP(ctx context.Context, networkUUID, serverUUID string) (string, error)
 }
 
 // NetworkList holds a list of available networks.
@@ -329,8 +331,13 @@ func (c *Client) GetNetwork(ctx context.Context, id string) (Network, error) {
 
 // CreateNetwork creates a network.
 //
+// Note: DHCP settings are validated locally (see ValidateNetworkCreateRequest) before the request is sent.
+//
 // See: https://gridscale.io/en//api-documentation/index.html#tag/network
 func (c *Client) CreateNetwork(ctx context.Context, body NetworkCreateRequest) (NetworkCreateResponse, error) {
+	if err := ValidateNetworkCreateRequest(body); err != nil {
+		return NetworkCreateResponse{}, err
+	}
 	r := gsRequest{
 		uri:    apiNetworkBase,
 		method: http.MethodPost,
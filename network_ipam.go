@@ -0,0 +1,330 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+)
+
+// maxReportedFreeAddresses is the maximum number of free addresses listed in a NetworkAddressReport.
+const maxReportedFreeAddresses = 256
+
+// NetworkAddressReport represents the usage of the IP addresses in the DHCP range of a private network.
+type NetworkAddressReport struct {
+	// The DHCP range of the network.
+	DHCPRange string
+
+	// Number of usable addresses in the DHCP range (without the network and broadcast addresses).
+	TotalAddresses int
+
+	// Number of addresses which are neither the gateway, nor reserved, pinned or auto-assigned.
+	FreeAddressCount int
+
+	// The first free addresses in ascending order, at most maxReportedFreeAddresses of them.
+	// Use FreeAddressCount for the number of all free addresses.
+	FreeAddresses []string
+
+	// Addresses which are used more than once, or used by a server although they are reserved
+	// or outside of the DHCP range.
+	Conflicts []NetworkAddressConflict
+}
+
+// NetworkAddressConflict represents an IP address which is used in a conflicting way.
+type NetworkAddressConflict struct {
+	// The conflicting IP address.
+	IP string
+
+	// Descriptions of the usages of the IP address, e.g. "gateway", "reserved subnet 192.168.0.0/30",
+	// "pinned to server <UUID>", "auto-assigned to server <UUID>" or "outside of DHCP range".
+	Usages []string
+}
+
+// AnalyzeNetworkAddresses computes free addresses and address conflicts of a private network
+// based on its DHCP range, gateway, DNS server, reserved subnets, pinned and auto-assigned servers.
+func AnalyzeNetworkAddresses(properties NetworkProperties) (NetworkAddressReport, error) {
+	report := NetworkAddressReport{DHCPRange: properties.DHCPRange}
+	addresses, err := newNetworkAddressUsage(properties)
+	if err != nil {
+		return report, err
+	}
+
+	for addr, addrUsages := range addresses.usages {
+		if !addresses.serverAddrs[addr] {
+			continue
+		}
+		for _, subnet := range addresses.reservedSubnets {
+			if subnet.Contains(addr) {
+				addrUsages = append(addrUsages, fmt.Sprintf("reserved subnet %s", subnet))
+			}
+		}
+		if !addresses.dhcpRange.Contains(addr) {
+			addrUsages = append(addrUsages, "outside of DHCP range")
+		}
+		if len(addrUsages) > 1 {
+			report.Conflicts = append(report.Conflicts, NetworkAddressConflict{IP: addr.String(), Usages: addrUsages})
+		}
+	}
+	sort.Slice(report.Conflicts, func(i, j int) bool {
+		return netip.MustParseAddr(report.Conflicts[i].IP).Less(netip.MustParseAddr(report.Conflicts[j].IP))
+	})
+
+	report.TotalAddresses = int(addressCount(addresses.first, addresses.last))
+	report.FreeAddressCount = addresses.freeAddressCount()
+	addr, ok := addresses.nextFree(addresses.first)
+	for ok && len(report.FreeAddresses) < maxReportedFreeAddresses {
+		report.FreeAddresses = append(report.FreeAddresses, addr.String())
+		addr, ok = addresses.nextFree(addr.Next())
+	}
+	return report, nil
+}
+
+// GetNetworkAddressReport returns the address usage report of a private network.
+// See AnalyzeNetworkAddresses.
+func (c *Client) GetNetworkAddressReport(ctx context.Context, networkUUID string) (NetworkAddressReport, error) {
+	network, err := c.GetNetwork(ctx, networkUUID)
+	if err != nil {
+		return NetworkAddressReport{}, err
+	}
+	return AnalyzeNetworkAddresses(network.Properties)
+}
+
+// PinNextFreeNetworkIP allocates the next free IP address of a private network and pins it to a server.
+// It returns the pinned IP address.
+func (c *Client) PinNextFreeNetworkIP(ctx context.Context, networkUUID, serverUUID string) (string, error) {
+	if !isValidUUID(serverUUID) {
+		return "", errors.New("'serverUUID' is invalid")
+	}
+	network, err := c.GetNetwork(ctx, networkUUID)
+	if err != nil {
+		return "", err
+	}
+	addresses, err := newNetworkAddressUsage(network.Properties)
+	if err != nil {
+		return "", err
+	}
+	addr, ok := addresses.nextFree(addresses.first)
+	if !ok {
+		return "", fmt.Errorf("no free IP address left in network %s", networkUUID)
+	}
+	ip := addr.String()
+	err = c.UpdateNetworkPinnedServer(ctx, networkUUID, serverUUID, PinServerRequest{IP: ip})
+	return ip, err
+}
+
+// ValidateNetworkDHCPSettings validates DHCP settings of a network locally: the DHCP range must be
+// an IPv4 subnet, the gateway must be a usable address inside the range and the reserved subnets
+// must be inside the range.
+func ValidateNetworkDHCPSettings(dhcpRange, dhcpGateway string, dhcpReservedSubnets []string) error {
+	prefix, err := parseDHCPRange(dhcpRange)
+	if err != nil {
+		return err
+	}
+	if dhcpGateway != "" {
+		gateway, err := netip.ParseAddr(dhcpGateway)
+		if err != nil {
+			return fmt.Errorf("'DHCPGateway' is invalid: %v", err)
+		}
+		first, last := usableAddressRange(prefix)
+		if gateway.Less(first) || last.Less(gateway) {
+			return fmt.Errorf("'DHCPGateway' %s is not a usable address of the DHCP range %s", dhcpGateway, dhcpRange)
+		}
+	}
+	subnets, err := parseReservedSubnets(dhcpReservedSubnets)
+	if err != nil {
+		return err
+	}
+	for _, subnet := range subnets {
+		if subnet.Bits() < prefix.Bits() || !prefix.Contains(subnet.Addr()) {
+			return fmt.Errorf("reserved subnet %s is not inside the DHCP range %s", subnet, dhcpRange)
+		}
+	}
+	return nil
+}
+
+// ValidateNetworkCreateRequest validates the DHCP settings of a network create request.
+// Requests without a DHCP range are not validated, as the range is then chosen by the API.
+func ValidateNetworkCreateRequest(body NetworkCreateRequest) error {
+	if body.DHCPRange == "" {
+		return nil
+	}
+	return ValidateNetworkDHCPSettings(body.DHCPRange, body.DHCPGateway, body.DHCPReservedSubnet)
+}
+
+// parseDHCPRange parses the DHCP range of a network.
+func parseDHCPRange(dhcpRange string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(dhcpRange)
+	if err != nil {
+		return prefix, fmt.Errorf("'DHCPRange' is invalid: %v", err)
+	}
+	if !prefix.Addr().Is4() {
+		return prefix, errors.New("'DHCPRange' must be an IPv4 subnet")
+	}
+	return prefix.Masked(), nil
+}
+
+// parseReservedSubnets parses reserved subnets. Single addresses are treated as /32 subnets.
+func parseReservedSubnets(subnets []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(subnet)
+			if addrErr != nil {
+				return nil, fmt.Errorf("reserved subnet %s is invalid: %v", subnet, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// networkAddressUsage holds the parsed address usage of a private network.
+type networkAddressUsage struct {
+	// The DHCP range of the network.
+	dhcpRange netip.Prefix
+
+	// First and last usable addresses of the DHCP range.
+	first, last netip.Addr
+
+	// Reserved subnets of the DHCP range, without subnets nested in other ones.
+	reservedSubnets []netip.Prefix
+
+	// Usages of the addresses used by the gateway, DNS server and servers.
+	usages map[netip.Addr][]string
+
+	// Addresses used by servers.
+	serverAddrs map[netip.Addr]bool
+}
+
+// newNetworkAddressUsage parses the DHCP settings, pinned and auto-assigned servers of a private network.
+func newNetworkAddressUsage(properties NetworkProperties) (networkAddressUsage, error) {
+	var addresses networkAddressUsage
+	var err error
+	addresses.dhcpRange, err = parseDHCPRange(properties.DHCPRange)
+	if err != nil {
+		return addresses, err
+	}
+	addresses.first, addresses.last = usableAddressRange(addresses.dhcpRange)
+	subnets, err := parseReservedSubnets(properties.DHCPReservedSubnet)
+	if err != nil {
+		return addresses, err
+	}
+	// Subnets are either disjoint or nested, so dropping the nested ones leaves disjoint subnets.
+	sort.Slice(subnets, func(i, j int) bool {
+		return subnets[i].Bits() < subnets[j].Bits()
+	})
+	for _, subnet := range subnets {
+		if !isInSubnets(subnet.Addr(), addresses.reservedSubnets) {
+			addresses.reservedSubnets = append(addresses.reservedSubnets, subnet)
+		}
+	}
+
+	addresses.usages = make(map[netip.Addr][]string)
+	addresses.serverAddrs = make(map[netip.Addr]bool)
+	addUsage := func(ip, usage string, isServer bool) {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return
+		}
+		addresses.usages[addr] = append(addresses.usages[addr], usage)
+		addresses.serverAddrs[addr] = addresses.serverAddrs[addr] || isServer
+	}
+	addUsage(properties.DHCPGateway, "gateway", false)
+	addUsage(properties.DHCPDNS, "DNS server", false)
+	for _, server := range properties.PinnedServers {
+		addUsage(server.IP, fmt.Sprintf("pinned to server %s", server.ServerUUID), true)
+	}
+	for _, server := range properties.AutoAssignedServers {
+		addUsage(server.IP, fmt.Sprintf("auto-assigned to server %s", server.ServerUUID), true)
+	}
+	return addresses, nil
+}
+
+// nextFree returns the first free address of the DHCP range starting at an address, skipping
+// reserved subnets as a whole, or false if there is none.
+func (u networkAddressUsage) nextFree(addr netip.Addr) (netip.Addr, bool) {
+	for addr.IsValid() && !u.last.Less(addr) {
+		if subnet, ok := findSubnet(addr, u.reservedSubnets); ok {
+			addr = lastSubnetAddress(subnet).Next()
+			continue
+		}
+		if _, used := u.usages[addr]; !used {
+			return addr, true
+		}
+		addr = addr.Next()
+	}
+	return netip.Addr{}, false
+}
+
+// freeAddressCount returns the number of free addresses of the DHCP range without iterating over them.
+func (u networkAddressUsage) freeAddressCount() int {
+	count := addressCount(u.first, u.last)
+	for _, subnet := range u.reservedSubnets {
+		first, last := subnet.Addr(), lastSubnetAddress(subnet)
+		if first.Less(u.first) {
+			first = u.first
+		}
+		if u.last.Less(last) {
+			last = u.last
+		}
+		count -= addressCount(first, last)
+	}
+	for addr := range u.usages {
+		if !addr.Less(u.first) && !u.last.Less(addr) && !isInSubnets(addr, u.reservedSubnets) {
+			count--
+		}
+	}
+	return int(count)
+}
+
+// usableAddressRange returns the first and last usable addresses of an IPv4 subnet,
+// i.e. without the network and broadcast addresses.
+func usableAddressRange(prefix netip.Prefix) (netip.Addr, netip.Addr) {
+	first, last := prefix.Addr(), lastSubnetAddress(prefix)
+	if prefix.Bits() >= 31 {
+		return first, last
+	}
+	return first.Next(), last.Prev()
+}
+
+// lastSubnetAddress returns the last address of an IPv4 subnet.
+func lastSubnetAddress(prefix netip.Prefix) netip.Addr {
+	start := prefix.Addr().As4()
+	end := binary.BigEndian.Uint32(start[:]) | uint32(uint64(1)<<(32-prefix.Bits())-1)
+	var lastBytes [4]byte
+	binary.BigEndian.PutUint32(lastBytes[:], end)
+	return netip.AddrFrom4(lastBytes)
+}
+
+// addressCount returns the number of IPv4 addresses from first to last, or 0 if last is before first.
+func addressCount(first, last netip.Addr) int64 {
+	if last.Less(first) {
+		return 0
+	}
+	firstBytes, lastBytes := first.As4(), last.As4()
+	return int64(binary.BigEndian.Uint32(lastBytes[:])) - int64(binary.BigEndian.Uint32(firstBytes[:])) + 1
+}
+
+// findSubnet returns the subnet containing an address.
+func findSubnet(addr netip.Addr, subnets []netip.Prefix) (netip.Prefix, bool) {
+	for _, subnet := range subnets {
+		if subnet.Contains(addr) {
+			return subnet, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// isInSubnets checks if an address is in one of the given subnets.
+func isInSubnets(addr netip.Addr, subnets []netip.Prefix) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeNetworkAddresses(t *testing.T) {
+	network := getMockIPAMNetwork()
+	report, err := AnalyzeNetworkAddresses(network.Properties)
+	assert.Nil(t, err, "AnalyzeNetworkAddresses returned an error %v", err)
+	assert.Equal(t, 14, report.TotalAddresses)
+	// .1 is the gateway, .2-.3 are reserved, .4 is pinned, .5 is auto-assigned.
+	assert.Equal(t, "192.168.0.6", report.FreeAddresses[0])
+	assert.Equal(t, 9, len(report.FreeAddresses))
+	assert.Equal(t, 9, report.FreeAddressCount)
+	assert.Equal(t, []NetworkAddressConflict{
+		{
+			IP:     "192.168.0.2",
+			Usages: []string{"pinned to server " + dummyUUID, "reserved subnet 192.168.0.2/31"},
+		},
+		{
+			IP:     "192.168.0.5",
+			Usages: []string{"auto-assigned to server " + dummyUUID, "auto-assigned to server " + dummyUUID},
+		},
+		{
+			IP:     "192.168.1.1",
+			Usages: []string{"pinned to server " + dummyUUID, "outside of DHCP range"},
+		},
+	}, report.Conflicts)
+
+	// Large ranges are counted, and only the first free addresses are listed.
+	network.Properties.DHCPRange = "10.0.0.0/8"
+	network.Properties.DHCPGateway = "10.0.0.1"
+	network.Properties.DHCPReservedSubnet = []string{"10.0.0.0/16", "10.0.1.0/24", "10.255.255.254"}
+	network.Properties.PinnedServers = []ServerWithIP{{ServerUUID: dummyUUID, IP: "10.1.0.0"}}
+	network.Properties.AutoAssignedServers = nil
+	report, err = AnalyzeNetworkAddresses(network.Properties)
+	assert.Nil(t, err, "AnalyzeNetworkAddresses returned an error %v", err)
+	assert.Equal(t, 1<<24-2, report.TotalAddresses)
+	assert.Equal(t, 1<<24-2-(1<<16-1)-1-1, report.FreeAddressCount)
+	assert.Equal(t, maxReportedFreeAddresses, len(report.FreeAddresses))
+	assert.Equal(t, "10.1.0.1", report.FreeAddresses[0])
+
+	network.Properties.DHCPRange = "invalid"
+	_, err = AnalyzeNetworkAddresses(network.Properties)
+	assert.NotNil(t, err)
+}
+
+func TestClient_PinNextFreeNetworkIP(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var pinned PinServerRequest
+	mux.HandleFunc(path.Join(apiNetworkBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(getMockIPAMNetwork())
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiNetworkBase, dummyUUID, "pinned_servers", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		json.NewDecoder(r.Body).Decode(&pinned)
+	})
+	for _, test := range uuidCommonTestCases {
+		ip, err := client.PinNextFreeNetworkIP(emptyCtx, dummyUUID, test.testUUID)
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "PinNextFreeNetworkIP returned an error %v", err)
+			assert.Equal(t, "192.168.0.6", ip)
+			assert.Equal(t, "192.168.0.6", pinned.IP)
+		}
+	}
+}
+
+func TestValidateNetworkCreateRequest(t *testing.T) {
+	testCases := []struct {
+		request  NetworkCreateRequest
+		isFailed bool
+	}{
+		{
+			request:  NetworkCreateRequest{Name: "test"},
+			isFailed: false,
+		},
+		{
+			request: NetworkCreateRequest{
+				DHCPRange:          "192.168.0.0/24",
+				DHCPGateway:        "192.168.0.1",
+				DHCPReservedSubnet: []string{"192.168.0.128/25", "192.168.0.10"},
+			},
+			isFailed: false,
+		},
+		{
+			request:  NetworkCreateRequest{DHCPRange: "192.168.0.0/24", DHCPGateway: "192.168.1.1"},
+			isFailed: true,
+		},
+		{
+			request:  NetworkCreateRequest{DHCPRange: "192.168.0.0/24", DHCPGateway: "192.168.0.255"},
+			isFailed: true,
+		},
+		{
+			request:  NetworkCreateRequest{DHCPRange: "192.168.0.0/24", DHCPReservedSubnet: []string{"192.168.0.0/23"}},
+			isFailed: true,
+		},
+		{
+			request:  NetworkCreateRequest{DHCPRange: "192.168.0.0/24", DHCPReservedSubnet: []string{"10.0.0.0/30"}},
+			isFailed: true,
+		},
+		{
+			request:  NetworkCreateRequest{DHCPRange: "fd00::/64"},
+			isFailed: true,
+		},
+	}
+	for _, test := range testCases {
+		err := ValidateNetworkCreateRequest(test.request)
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "ValidateNetworkCreateRequest returned an error %v", err)
+		}
+	}
+}
+
+func getMockIPAMNetwork() Network {
+	network := getMockNetwork(false, "active")
+	network.Properties.DHCPActive = true
+	network.Properties.DHCPRange = "192.168.0.0/28"
+	network.Properties.DHCPGateway = "192.168.0.1"
+	network.Properties.DHCPReservedSubnet = []string{"192.168.0.2/31"}
+	network.Properties.PinnedServers = []ServerWithIP{
+		{ServerUUID: dummyUUID, IP: "192.168.0.2"},
+		{ServerUUID: dummyUUID, IP: "192.168.0.4"},
+		{ServerUUID: dummyUUID, IP: "192.168.1.1"},
+	}
+	network.Properties.AutoAssignedServers = []ServerWithIP{
+		{ServerUUID: dummyUUID, IP: "192.168.0.5"},
+		{ServerUUID: dummyUUID, IP: "192.168.0.5"},
+	}
+	return network
+}
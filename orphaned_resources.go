@@ -0,0 +1,332 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Default values of OrphanedResourceOptions.
+const (
+	defaultPoweredOffFor      = 30 * 24 * time.Hour
+	defaultOrphanedCostWindow = 30 * 24 * time.Hour
+)
+
+// OrphanedResourceOptions represents the options of a search for orphaned and idle resources.
+type OrphanedResourceOptions struct {
+	// Servers powered off for longer are reported. The time of the last change is used as power-off time.
+	// Leave it 0 to use 30 days.
+	PoweredOffFor time.Duration
+
+	// Snapshots older than MaxSnapshotAge are reported. Leave it 0 to ignore the age.
+	MaxSnapshotAge time.Duration
+
+	// Snapshots which would be deleted by the retention policy are reported, see PlanSnapshotRetention. Optional.
+	SnapshotRetention *SnapshotRetentionPolicy
+
+	// If set, the cost of each resource within the cost window is estimated from its usage. Optional.
+	PriceList *PriceList
+
+	// Time range before now used to estimate the costs. Leave it 0 to use 30 days.
+	CostWindow time.Duration
+
+	// If set, it is called for every found resource, and the resource is deleted if it returns true.
+	// Leave it nil to only report the resources.
+	Confirm func(resource OrphanedResource) bool
+}
+
+// OrphanedResource represents a resource which is probably not needed anymore.
+type OrphanedResource struct {
+	// Type of the resource, e.g. ServerUsageResource or RocketStorageUsageResource.
+	ResourceType string
+
+	// The UUID of the resource.
+	ObjectUUID string
+
+	// The name of the resource.
+	Name string
+
+	// List of labels.
+	Labels []string
+
+	// The UUID of the storage of a snapshot. Empty for other resource types.
+	StorageUUID string
+
+	// Why the resource is reported, e.g. "not attached to any server".
+	Reason string
+
+	// Estimated cost within the cost window. Only set if a price list is given.
+	Cost float64
+
+	// True if the resource was deleted.
+	Deleted bool
+}
+
+// OrphanedResourceReport represents the result of a search for orphaned and idle resources.
+type OrphanedResourceReport struct {
+	// Found resources: servers, snapshots, storages, IP addresses, ISO images and templates, in this order,
+	// which is also the order of deletion.
+	Resources []OrphanedResource
+
+	// Currency of the costs. Empty if no price list is given.
+	Currency string
+
+	// Estimated cost of all found resources within the cost window.
+	TotalCost float64
+
+	// Product numbers used by found resources but missing in the price list, their usage is not
+	// included in the costs.
+	UnpricedProducts []int
+}
+
+// FindOrphanedResources cross-references servers, storages, snapshots, IP addresses, ISO images and templates
+// to find waste:
+//   - storages not attached to any server,
+//   - IP addresses related to neither a server nor a load balancer, including failover IPs (see GetUnusedIPs),
+//   - servers powered off for longer than PoweredOffFor,
+//   - snapshots older than MaxSnapshotAge or not kept by SnapshotRetention,
+//   - private ISO images not attached to any server,
+//   - private templates not used by any storage (see StorageProperties.LastUsedTemplate).
+//
+// Resources confirmed by opts.Confirm are deleted.
+func (c *Client) FindOrphanedResources(ctx context.Context, opts OrphanedResourceOptions) (OrphanedResourceReport, error) {
+	if opts.PoweredOffFor < 0 || opts.MaxSnapshotAge < 0 || opts.CostWindow < 0 {
+		return OrphanedResourceReport{}, errors.New("'PoweredOffFor', 'MaxSnapshotAge' and 'CostWindow' must not be negative")
+	}
+	if opts.SnapshotRetention != nil {
+		_, err := PlanSnapshotRetention(nil, *opts.SnapshotRetention)
+		if err != nil {
+			return OrphanedResourceReport{}, err
+		}
+	}
+	if opts.PoweredOffFor == 0 {
+		opts.PoweredOffFor = defaultPoweredOffFor
+	}
+	if opts.CostWindow == 0 {
+		opts.CostWindow = defaultOrphanedCostWindow
+	}
+	now := time.Now()
+	var report OrphanedResourceReport
+
+	servers, err := c.GetServerList(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, server := range servers {
+		props := server.Properties
+		if !props.Power && props.ChangeTime.Before(now.Add(-opts.PoweredOffFor)) {
+			report.Resources = append(report.Resources, OrphanedResource{
+				ResourceType: ServerUsageResource,
+				ObjectUUID:   props.ObjectUUID,
+				Name:         props.Name,
+				Labels:       props.Labels,
+				Reason:       fmt.Sprintf("powered off since %s", props.ChangeTime),
+			})
+		}
+	}
+
+	storages, err := c.GetStorageList(ctx)
+	if err != nil {
+		return report, err
+	}
+	if opts.MaxSnapshotAge > 0 || opts.SnapshotRetention != nil {
+		for _, storage := range storages {
+			snapshots, err := c.GetStorageSnapshotList(ctx, storage.Properties.ObjectUUID)
+			if err != nil {
+				return report, err
+			}
+			report.Resources = append(report.Resources, findOrphanedSnapshots(storage.Properties.ObjectUUID, snapshots, opts, now)...)
+		}
+	}
+	usedTemplates := make(map[string]bool)
+	for _, storage := range storages {
+		props := storage.Properties
+		usedTemplates[props.LastUsedTemplate] = true
+		if len(props.Relations.Servers) == 0 {
+			report.Resources = append(report.Resources, OrphanedResource{
+				ResourceType: storageUsageResource(props.StorageVariant),
+				ObjectUUID:   props.ObjectUUID,
+				Name:         props.Name,
+				Labels:       props.Labels,
+				Reason:       "not attached to any server",
+			})
+		}
+	}
+
+	ips, err := c.GetIPList(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, ip := range ips {
+		props := ip.Properties
+		if isIPUnused(ip) {
+			reason := "related to neither a server nor a load balancer"
+			if props.Failover {
+				reason = "failover IP " + reason
+			}
+			report.Resources = append(report.Resources, OrphanedResource{
+				ResourceType: IPUsageResource,
+				ObjectUUID:   props.ObjectUUID,
+				Name:         props.IP,
+				Labels:       props.Labels,
+				Reason:       reason,
+			})
+		}
+	}
+
+	isoImages, err := c.GetISOImageList(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, isoImage := range isoImages {
+		props := isoImage.Properties
+		if props.Private && len(props.Relations.Servers) == 0 {
+			report.Resources = append(report.Resources, OrphanedResource{
+				ResourceType: ISOImageUsageResource,
+				ObjectUUID:   props.ObjectUUID,
+				Name:         props.Name,
+				Labels:       props.Labels,
+				Reason:       "not attached to any server",
+			})
+		}
+	}
+
+	templates, err := c.GetTemplateList(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, template := range templates {
+		props := template.Properties
+		if props.Private && !usedTemplates[props.ObjectUUID] {
+			report.Resources = append(report.Resources, OrphanedResource{
+				ResourceType: TemplateUsageResource,
+				ObjectUUID:   props.ObjectUUID,
+				Name:         props.Name,
+				Labels:       props.Labels,
+				Reason:       "not used by any storage",
+			})
+		}
+	}
+
+	if opts.PriceList != nil {
+		err = c.estimateOrphanedResourceCosts(ctx, &report, *opts.PriceList, now.Add(-opts.CostWindow))
+		if err != nil {
+			return report, err
+		}
+	}
+	if opts.Confirm == nil {
+		return report, nil
+	}
+	for i, resource := range report.Resources {
+		if !opts.Confirm(resource) {
+			continue
+		}
+		err = c.deleteOrphanedResource(ctx, resource)
+		if err != nil {
+			return report, fmt.Errorf("deleting %s %s failed: %v", resource.ResourceType, resource.ObjectUUID, err)
+		}
+		report.Resources[i].Deleted = true
+	}
+	return report, nil
+}
+
+// findOrphanedSnapshots returns the snapshots of a storage which are too old or not kept by the retention policy.
+func findOrphanedSnapshots(storageID string, snapshots []StorageSnapshot, opts OrphanedResourceOptions, now time.Time) []OrphanedResource {
+	notKept := make(map[string]bool)
+	if opts.SnapshotRetention != nil {
+		// The policy is validated by FindOrphanedResources.
+		plan, err := PlanSnapshotRetention(snapshots, *opts.SnapshotRetention)
+		if err == nil {
+			for _, snapshot := range plan.Deleted {
+				notKept[snapshot.Properties.ObjectUUID] = true
+			}
+		}
+	}
+	var resources []OrphanedResource
+	for _, snapshot := range snapshots {
+		props := snapshot.Properties
+		var reason string
+		switch {
+		case opts.MaxSnapshotAge > 0 && props.CreateTime.Before(now.Add(-opts.MaxSnapshotAge)):
+			reason = fmt.Sprintf("created at %s, older than %s", props.CreateTime, opts.MaxSnapshotAge)
+		case notKept[props.ObjectUUID]:
+			reason = "not kept by the retention policy"
+		default:
+			continue
+		}
+		resources = append(resources, OrphanedResource{
+			ResourceType: SnapshotUsageResource,
+			ObjectUUID:   props.ObjectUUID,
+			Name:         props.Name,
+			Labels:       props.Labels,
+			StorageUUID:  storageID,
+			Reason:       reason,
+		})
+	}
+	return resources
+}
+
+// estimateOrphanedResourceCosts sets the costs of the resources of the report from their daily usage since from,
+// as the usage API only reports usage per interval. Products without price are skipped and listed in report.UnpricedProducts.
+func (c *Client) estimateOrphanedResourceCosts(ctx context.Context, report *OrphanedResourceReport, priceList PriceList, from time.Time) error {
+	usages, err := c.GetAllResourcesUsage(ctx, UsageQuery{
+		Level:    ProjectLevelUsage,
+		From:     from,
+		Interval: DayIntervalVariable,
+	})
+	if err != nil {
+		return err
+	}
+	costs := make(map[string]float64)
+	unpricedProducts := make(map[string][]int)
+	for _, usage := range usages {
+		for _, interval := range usage.UsagePerInterval {
+			cost, unpriced := priceList.UsageCost(interval.AccumulatedUsage)
+			costs[usage.ObjectUUID] += cost
+			unpricedProducts[usage.ObjectUUID] = append(unpricedProducts[usage.ObjectUUID], unpriced...)
+		}
+	}
+	report.Currency = priceList.Currency
+	isUnpriced := make(map[int]bool)
+	for i, resource := range report.Resources {
+		report.Resources[i].Cost = costs[resource.ObjectUUID]
+		report.TotalCost += costs[resource.ObjectUUID]
+		for _, productNumber := range unpricedProducts[resource.ObjectUUID] {
+			if !isUnpriced[productNumber] {
+				isUnpriced[productNumber] = true
+				report.UnpricedProducts = append(report.UnpricedProducts, productNumber)
+			}
+		}
+	}
+	sort.Ints(report.UnpricedProducts)
+	return nil
+}
+
+// deleteOrphanedResource deletes a resource found by FindOrphanedResources.
+func (c *Client) deleteOrphanedResource(ctx context.Context, resource OrphanedResource) error {
+	switch resource.ResourceType {
+	case ServerUsageResource:
+		return c.DeleteServer(ctx, resource.ObjectUUID)
+	case SnapshotUsageResource:
+		return c.DeleteStorageSnapshot(ctx, resource.StorageUUID, resource.ObjectUUID)
+	case DistributedStorageUsageResource, RocketStorageUsageResource:
+		return c.DeleteStorage(ctx, resource.ObjectUUID)
+	case IPUsageResource:
+		return c.DeleteIP(ctx, resource.ObjectUUID)
+	case ISOImageUsageResource:
+		return c.DeleteISOImage(ctx, resource.ObjectUUID)
+	case TemplateUsageResource:
+		return c.DeleteTemplate(ctx, resource.ObjectUUID)
+	}
+	return fmt.Errorf("resource type %s is not supported", resource.ResourceType)
+}
+
+// storageUsageResource returns the usage resource type of a storage variant.
+func storageUsageResource(variant string) string {
+	if StorageVariant(variant) == LocalStorageVariant {
+		return RocketStorageUsageResource
+	}
+	return DistributedStorageUsageResource
+}
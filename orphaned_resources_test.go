@@ -0,0 +1,173 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_FindOrphanedResources(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	const (
+		usedStorageUUID   = "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+		unusedStorageUUID = "4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d"
+		snapshotUUID      = "5c6d9b4e-3f4a-4b7c-8d1e-2f3a4b5c6d7e"
+		usedTemplateUUID  = "6d7e0c5f-4a5b-4c8d-9e2f-3a4b5c6d7e8f"
+	)
+	longAgo := GSTime{time.Now().Add(-60 * 24 * time.Hour)}
+	lists := map[string]interface{}{
+		apiServerBase: ServerList{List: map[string]ServerProperties{
+			dummyUUID:       {ObjectUUID: dummyUUID, Name: "stopped", ChangeTime: longAgo},
+			usedStorageUUID: {ObjectUUID: usedStorageUUID, Name: "running", Power: true, ChangeTime: longAgo},
+		}},
+		apiStorageBase: StorageList{List: map[string]StorageProperties{
+			usedStorageUUID: {
+				ObjectUUID:       usedStorageUUID,
+				Name:             "root",
+				LastUsedTemplate: usedTemplateUUID,
+				Relations:        StorageRelations{Servers: []StorageServerRelation{{ObjectUUID: usedStorageUUID}}},
+			},
+			unusedStorageUUID: {ObjectUUID: unusedStorageUUID, Name: "data", StorageVariant: string(LocalStorageVariant)},
+		}},
+		path.Join(apiStorageBase, usedStorageUUID, "snapshots"): StorageSnapshotList{},
+		path.Join(apiStorageBase, unusedStorageUUID, "snapshots"): StorageSnapshotList{List: map[string]StorageSnapshotProperties{
+			snapshotUUID:    {ObjectUUID: snapshotUUID, Name: "old", CreateTime: longAgo},
+			usedStorageUUID: {ObjectUUID: usedStorageUUID, Name: "new", CreateTime: GSTime{time.Now()}},
+		}},
+		apiIPBase: IPList{List: map[string]IPProperties{
+			dummyUUID:         {ObjectUUID: dummyUUID, IP: "192.0.2.1"},
+			usedStorageUUID:   {ObjectUUID: usedStorageUUID, IP: "192.0.2.2", Failover: true},
+			unusedStorageUUID: {ObjectUUID: unusedStorageUUID, IP: "192.0.2.3", Relations: IPRelations{Loadbalancers: []IPLoadbalancer{{LoadbalancerUUID: dummyUUID}}}},
+		}},
+		apiISOBase: ISOImageList{List: map[string]ISOImageProperties{
+			dummyUUID:       {ObjectUUID: dummyUUID, Name: "installer", Private: true},
+			usedStorageUUID: {ObjectUUID: usedStorageUUID, Name: "public"},
+		}},
+		apiTemplateBase: TemplateList{List: map[string]TemplateProperties{
+			dummyUUID:        {ObjectUUID: dummyUUID, Name: "golden", Private: true},
+			usedTemplateUUID: {ObjectUUID: usedTemplateUUID, Name: "used", Private: true},
+		}},
+	}
+	for uri, list := range lists {
+		res, _ := json.Marshal(list)
+		mux.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			fmt.Fprint(w, string(res))
+		})
+	}
+	var deleted []string
+	mux.HandleFunc("/objects/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, "")
+			return
+		}
+		w.WriteHeader(404)
+	})
+	mux.HandleFunc(apiProjectLevelUsage+"/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, DayIntervalVariable, r.URL.Query().Get("interval_variable"))
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if strings.HasSuffix(r.URL.Path, IPUsageResource) {
+			// A resource without usage in the cost window is reported without intervals.
+			res, _ := json.Marshal(IPsUsage{ResourcesUsage: []IPUsageProperties{{ObjectUUID: dummyUUID}}})
+			fmt.Fprint(w, string(res))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, RocketStorageUsageResource) {
+			res, _ := json.Marshal(RocketStoragesUsage{ResourcesUsage: []StorageUsageProperties{{
+				ObjectUUID:       unusedStorageUUID,
+				UsagePerInterval: []UsagePerInterval{{AccumulatedUsage: []Usage{{ProductNumber: 10, Value: 40}, {ProductNumber: 11, Value: 5}}}},
+			}}})
+			fmt.Fprint(w, string(res))
+			return
+		}
+		fmt.Fprint(w, "{}")
+	})
+
+	priceList, err := NewPriceList("EUR", ProductPrice{ProductNumber: 10, UnitPrice: 0.25})
+	assert.Nil(t, err, "NewPriceList returned an error %v", err)
+	report, err := client.FindOrphanedResources(emptyCtx, OrphanedResourceOptions{
+		MaxSnapshotAge: 7 * 24 * time.Hour,
+		PriceList:      &priceList,
+		Confirm: func(resource OrphanedResource) bool {
+			return resource.ResourceType == SnapshotUsageResource || resource.ResourceType == RocketStorageUsageResource
+		},
+	})
+	assert.Nil(t, err, "FindOrphanedResources returned an error %v", err)
+	var found []string
+	reasons := make(map[string]string)
+	for _, resource := range report.Resources {
+		found = append(found, resource.ResourceType+" "+resource.Name)
+		reasons[resource.Name] = resource.Reason
+	}
+	assert.ElementsMatch(t, []string{
+		"servers stopped",
+		"snapshots old",
+		"rocket_storages data",
+		"ip_addresses 192.0.2.1",
+		"ip_addresses 192.0.2.2",
+		"iso_images installer",
+		"templates golden",
+	}, found)
+	assert.Equal(t, "EUR", report.Currency)
+	assert.Equal(t, 10.0, report.TotalCost)
+	assert.Equal(t, []int{11}, report.UnpricedProducts)
+	assert.Equal(t, OrphanedResource{
+		ResourceType: RocketStorageUsageResource,
+		ObjectUUID:   unusedStorageUUID,
+		Name:         "data",
+		Reason:       "not attached to any server",
+		Cost:         10,
+		Deleted:      true,
+	}, report.Resources[2])
+	assert.Equal(t, unusedStorageUUID, report.Resources[1].StorageUUID)
+	assert.True(t, report.Resources[1].Deleted)
+	assert.False(t, report.Resources[0].Deleted)
+	assert.Equal(t, "related to neither a server nor a load balancer", reasons["192.0.2.1"])
+	for _, resource := range report.Resources {
+		if resource.Name == "192.0.2.1" {
+			assert.Equal(t, 0.0, resource.Cost)
+		}
+	}
+	assert.Equal(t, "failover IP related to neither a server nor a load balancer", reasons["192.0.2.2"])
+	assert.Equal(t, []string{
+		path.Join(apiStorageBase, unusedStorageUUID, "snapshots", snapshotUUID),
+		path.Join(apiStorageBase, unusedStorageUUID),
+	}, deleted)
+
+	_, err = client.FindOrphanedResources(emptyCtx, OrphanedResourceOptions{SnapshotRetention: &SnapshotRetentionPolicy{}})
+	assert.NotNil(t, err)
+	_, err = client.FindOrphanedResources(emptyCtx, OrphanedResourceOptions{PoweredOffFor: -time.Hour})
+	assert.NotNil(t, err)
+}
+
+func TestFindOrphanedSnapshots(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	var snapshots []StorageSnapshot
+	for i := 0; i < 3; i++ {
+		snapshots = append(snapshots, StorageSnapshot{Properties: StorageSnapshotProperties{
+			ObjectUUID: fmt.Sprintf("snapshot-%d", i),
+			Status:     resourceActiveStatus,
+			CreateTime: GSTime{now.AddDate(0, 0, -i)},
+		}})
+	}
+	resources := findOrphanedSnapshots(dummyUUID, snapshots, OrphanedResourceOptions{
+		SnapshotRetention: &SnapshotRetentionPolicy{Daily: 1},
+	}, now)
+	assert.Equal(t, 2, len(resources))
+	assert.Equal(t, "not kept by the retention policy", resources[0].Reason)
+
+	resources = findOrphanedSnapshots(dummyUUID, snapshots, OrphanedResourceOptions{MaxSnapshotAge: 36 * time.Hour}, now)
+	assert.Equal(t, 1, len(resources))
+	assert.Equal(t, "snapshot-2", resources[0].ObjectUUID)
+	assert.Equal(t, dummyUUID, resources[0].StorageUUID)
+}
@@ -18,6 +18,16 @@ type PaaSOperator interface {
 	GetPaaSTemplateList(ctx context.Context) ([]PaaSTemplate, error)
 	GetDeletedPaaSServices(ctx context.Context) ([]PaaSService, error)
 	RenewK8sCredentials(ctx context.Context, id string) error
+	GetK8sKubeconfig(ctx context.Context, id string) (K8sKubeconfig, error)
+	RunK8sCredentialRenewer(ctx context.Context, id string, opts K8sCredentialRenewOptions) error
+	GetK8sNodePools(ctx context.Context, id string) ([]K8sNodePool, error)
+	ScaleK8sNodePool(ctx context.Context, id, poolName string, nodeCount int) error
+	UpdateK8sNodePool(ctx context.Context, id, poolName string, body K8sNodePoolUpdateRequest) error
+	GetPaaSConnectionInfo(ctx context.Context, id string, opts PaaSConnectionOptions) (PaaSConnectionInfo, error)
+	ValidatePaaSServiceCreateRequest(ctx context.Context, body PaaSServiceCreateRequest) (PaaSServiceCreateRequest, error)
+	ValidatePaaSServiceUpdateRequest(ctx context.Context, id string, body PaaSServiceUpdateRequest) error
+	GetPaaSUpgradePaths(ctx context.Context, id string) ([]PaaSUpgradePath, error)
+	UpgradePaaSService(ctx context.Context, id, targetTemplateUUID string) (PaaSUpgradePath, error)
 	GetPaaSSecurityZoneList(ctx context.Context) ([]PaaSSecurityZone, error)
 	GetPaaSSecurityZone(ctx context.Context, id string) (PaaSSecurityZone, error)
 	CreatePaaSSecurityZone(ctx context.Context, body PaaSSecurityZoneCreateRequest) (PaaSSecurityZoneCreateResponse, error)
@@ -481,6 +491,7 @@ func (c *Client) GetPaaSServiceList(ctx context.Context) ([]PaaSService, error)
 }
 
 // CreatePaaSService creates a new PaaS service.
+// Use ValidatePaaSServiceCreateRequest to check the parameters against the template before.
 //
 // See: https://gridscale.io/en//api-documentation/index.html#operation/createPaasService
 func (c *Client) CreatePaaSService(ctx context.Context, body PaaSServiceCreateRequest) (PaaSServiceCreateResponse, error) {
@@ -512,6 +523,7 @@ func (c *Client) GetPaaSService(ctx context.Context, id string) (PaaSService, er
 }
 
 // UpdatePaaSService updates a specific PaaS Service based on a given id.
+// Use ValidatePaaSServiceUpdateRequest to check the parameters against the template before.
 //
 // See: https://gridscale.io/en//api-documentation/index.html#operation/updatePaasService
 func (c *Client) UpdatePaaSService(ctx context.Context, id string, body PaaSServiceUpdateRequest) error {
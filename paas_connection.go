@@ -0,0 +1,280 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PaaSConnectionKind is the kind of connection info of a PaaS service.
+type PaaSConnectionKind string
+
+// All available PaaS connection kinds.
+const (
+	PostgresConnection  PaaSConnectionKind = "postgres"
+	MySQLConnection     PaaSConnectionKind = "mysql"
+	RedisConnection     PaaSConnectionKind = "redis"
+	MemcachedConnection PaaSConnectionKind = "memcached"
+	K8sConnection       PaaSConnectionKind = "k8s"
+)
+
+// paasConnectionKindNames maps substrings of listen port names and template categories to connection kinds.
+var paasConnectionKindNames = []struct {
+	name string
+	kind PaaSConnectionKind
+}{
+	{"postgres", PostgresConnection},
+	{"mysql", MySQLConnection},
+	{"mariadb", MySQLConnection},
+	{"redis", RedisConnection},
+	{"memcached", MemcachedConnection},
+	{"kubernetes", K8sConnection},
+	{"k8s", K8sConnection},
+}
+
+// PaaSConnectionOptions represents the options for building the connection info of a PaaS service.
+type PaaSConnectionOptions struct {
+	// Address family of the listen addresses to use. Leave it 0 to use IPv4Type.
+	Family IPAddressType
+
+	// Database name added to Postgres and MySQL DSNs. Optional.
+	Database string
+}
+
+// PaaSConnectionInfo represents ready-to-use connection info of a PaaS service.
+type PaaSConnectionInfo struct {
+	// Kind of the service.
+	Kind PaaSConnectionKind
+
+	// Listen address of the service. Empty for k8s clusters.
+	Host string
+
+	// Listen port of the service, preferably the one named after its kind, e.g. "postgres" rather than
+	// a metrics exporter. 0 for k8s clusters.
+	Port int
+
+	// All listen addresses of the service as host:port, e.g. for memcached clients.
+	Addresses []string
+
+	// Username of the service's credentials.
+	Username string
+
+	// Password of the service's credentials.
+	Password string
+
+	// Connection URL: a DSN for Postgres and MySQL, a redis:// URL for Redis, the address list for memcached,
+	// and the API server of the current context for k8s clusters. The MySQL DSN uses the format of
+	// github.com/go-sql-driver/mysql, which has no escaping: the username and password are included as they are,
+	// as by mysql.Config.FormatDSN. The driver splits the DSN at the last '/' and the last '@' before it, so the
+	// password may contain any character, but the username must not contain ':'.
+	URL string
+
+	// Kubeconfig of a k8s cluster.
+	Kubeconfig string
+}
+
+// GetPaaSConnectionInfo returns the connection info of a PaaS service, see NewPaaSConnectionInfo.
+func (c *Client) GetPaaSConnectionInfo(ctx context.Context, id string, opts PaaSConnectionOptions) (PaaSConnectionInfo, error) {
+	service, err := c.GetPaaSService(ctx, id)
+	if err != nil {
+		return PaaSConnectionInfo{}, err
+	}
+	return NewPaaSConnectionInfo(service.Properties, opts)
+}
+
+// NewPaaSConnectionInfo builds the connection info of a PaaS service from its listen ports and credentials.
+// The kind of the service is derived from the names of its listen ports, its credentials and its template category.
+func NewPaaSConnectionInfo(service PaaSServiceProperties, opts PaaSConnectionOptions) (PaaSConnectionInfo, error) {
+	if opts.Family == 0 {
+		opts.Family = IPv4Type
+	}
+	if opts.Family != IPv4Type && opts.Family != IPv6Type {
+		return PaaSConnectionInfo{}, fmt.Errorf("address family %d is invalid", opts.Family)
+	}
+	info := PaaSConnectionInfo{Kind: paasConnectionKind(service)}
+	if info.Kind == "" {
+		return info, fmt.Errorf("connection info of PaaS service %s is not supported", service.ObjectUUID)
+	}
+	for _, credential := range service.Credentials {
+		if info.Kind == K8sConnection && credential.KubeConfig != "" {
+			info.Kubeconfig = credential.KubeConfig
+			break
+		}
+		if info.Kind != K8sConnection && (credential.Username != "" || credential.Password != "") {
+			info.Username = credential.Username
+			info.Password = credential.Password
+			break
+		}
+	}
+	if info.Kind == K8sConnection {
+		if info.Kubeconfig == "" {
+			return info, fmt.Errorf("PaaS service %s has no kubeconfig", service.ObjectUUID)
+		}
+		config, err := ParseKubeconfig([]byte(info.Kubeconfig))
+		if err != nil {
+			return info, err
+		}
+		// Merging into an empty kubeconfig resolves the cluster of the current context.
+		current, err := MergeKubeconfig(Kubeconfig{}, config, "current", true)
+		if err != nil {
+			return info, err
+		}
+		cluster, _ := current.Clusters[0].Fields["cluster"].(map[string]interface{})
+		info.URL, _ = cluster["server"].(string)
+		return info, nil
+	}
+
+	hosts := make([]string, 0, len(service.ListenPorts))
+	for host := range service.ListenPorts {
+		ip := net.ParseIP(host)
+		if ip != nil && (ip.To4() != nil) == (opts.Family == IPv4Type) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	var isKindPort bool
+	for _, host := range hosts {
+		ports := service.ListenPorts[host]
+		names := make([]string, 0, len(ports))
+		for name := range ports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			info.Addresses = append(info.Addresses, net.JoinHostPort(host, strconv.Itoa(ports[name])))
+			matches := isPaaSConnectionKindPort(name, info.Kind)
+			if info.Host == "" || (matches && !isKindPort) {
+				info.Host = host
+				info.Port = ports[name]
+				isKindPort = matches
+			}
+		}
+	}
+	if len(info.Addresses) == 0 {
+		return info, fmt.Errorf("PaaS service %s has no IPv%d listen address", service.ObjectUUID, opts.Family)
+	}
+
+	address := net.JoinHostPort(info.Host, strconv.Itoa(info.Port))
+	switch info.Kind {
+	case PostgresConnection:
+		u := url.URL{Scheme: "postgres", User: url.UserPassword(info.Username, info.Password), Host: address, Path: "/" + opts.Database}
+		info.URL = u.String()
+	case MySQLConnection:
+		info.URL = fmt.Sprintf("%s:%s@tcp(%s)/%s", info.Username, info.Password, address, opts.Database)
+	case RedisConnection:
+		u := url.URL{Scheme: "redis", Host: address}
+		if info.Password != "" {
+			u.User = url.UserPassword(info.Username, info.Password)
+		}
+		info.URL = u.String()
+	case MemcachedConnection:
+		info.URL = strings.Join(info.Addresses, ",")
+	}
+	return info, nil
+}
+
+// Env returns the connection info as environment variables named with the given prefix,
+// e.g. PREFIX_HOST, PREFIX_PORT, PREFIX_URL. Empty values are left out. PREFIX_KUBECONFIG holds the contents
+// of the kubeconfig, not a path. Without prefix, the names clash with common variables like HOST and KUBECONFIG,
+// so SetEnv and WriteEnvFile require one.
+func (i PaaSConnectionInfo) Env(prefix string) map[string]string {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	values := map[string]string{
+		"HOST":       i.Host,
+		"ADDRESSES":  strings.Join(i.Addresses, ","),
+		"USERNAME":   i.Username,
+		"PASSWORD":   i.Password,
+		"URL":        i.URL,
+		"KUBECONFIG": i.Kubeconfig,
+	}
+	if i.Port != 0 {
+		values["PORT"] = strconv.Itoa(i.Port)
+	}
+	env := make(map[string]string, len(values))
+	for key, value := range values {
+		if value != "" {
+			env[prefix+key] = value
+		}
+	}
+	return env
+}
+
+// SetEnv sets the environment variables of the connection info in the current process, see Env.
+func (i PaaSConnectionInfo) SetEnv(prefix string) error {
+	if prefix == "" {
+		return errors.New("'prefix' is required")
+	}
+	for key, value := range i.Env(prefix) {
+		err := os.Setenv(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteEnvFile writes the environment variables of the connection info to a .env file, see Env.
+// Values are double-quoted and escaped, and the file is only readable by its owner as it contains secrets.
+func (i PaaSConnectionInfo) WriteEnvFile(path, prefix string) error {
+	if prefix == "" {
+		return errors.New("'prefix' is required")
+	}
+	env := i.Env(prefix)
+	if len(env) == 0 {
+		return errors.New("connection info is empty")
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, strconv.Quote(env[key]))
+	}
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// paasConnectionKind returns the connection kind of a PaaS service, or an empty kind if it is not supported.
+func paasConnectionKind(service PaaSServiceProperties) PaaSConnectionKind {
+	for _, credential := range service.Credentials {
+		if credential.KubeConfig != "" {
+			return K8sConnection
+		}
+	}
+	names := []string{strings.ToLower(service.ServiceTemplateCategory)}
+	for _, ports := range service.ListenPorts {
+		for name := range ports {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	for _, credential := range service.Credentials {
+		names = append(names, strings.ToLower(credential.Type))
+	}
+	for _, kindName := range paasConnectionKindNames {
+		for _, name := range names {
+			if strings.Contains(name, kindName.name) {
+				return kindName.kind
+			}
+		}
+	}
+	return ""
+}
+
+// isPaaSConnectionKindPort checks if the name of a listen port belongs to the connection kind.
+func isPaaSConnectionKindPort(name string, kind PaaSConnectionKind) bool {
+	for _, kindName := range paasConnectionKindNames {
+		if kindName.kind == kind && strings.Contains(strings.ToLower(name), kindName.name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+)
+
+// Parameter types of a PaaS template's parameters schema.
+const (
+	paasParameterTypeString  = "string"
+	paasParameterTypeInteger = "integer"
+	paasParameterTypeFloat   = "float"
+	paasParameterTypeNumber  = "number"
+	paasParameterTypeBoolean = "boolean"
+	paasParameterTypeList    = "list"
+	paasParameterTypeDict    = "dict"
+)
+
+// ValidatePaaSParameters checks parameters for creating a PaaS service against the parameters schema
+// of a PaaS template: unknown parameter names, missing required parameters, types, minimum and maximum,
+// allowed values and regular expressions. All violations are returned in one error.
+func ValidatePaaSParameters(schema map[string]Parameter, params map[string]interface{}) error {
+	var errs []error
+	for _, name := range sortedPaaSParameterNames(schema) {
+		if _, ok := params[name]; !ok && schema[name].Required {
+			errs = append(errs, fmt.Errorf("parameter '%s' is required", name))
+		}
+	}
+	errs = append(errs, validatePaaSParameterValues(schema, params)...)
+	return joinErrors(errs)
+}
+
+// ValidatePaaSParameterUpdate checks parameters for updating a PaaS service against the parameters schema
+// of a PaaS template, like ValidatePaaSParameters. Parameters which are not updated are not required,
+// and immutable parameters must not differ from the current parameters of the service.
+func ValidatePaaSParameterUpdate(schema map[string]Parameter, current, update map[string]interface{}) error {
+	errs := validatePaaSParameterValues(schema, update)
+	for _, name := range sortedPaaSParameterNames(schema) {
+		newValue, ok := update[name]
+		if !ok || !schema[name].Immutable {
+			continue
+		}
+		currentValue, ok := current[name]
+		if ok && !equalPaaSParameterValues(currentValue, newValue) {
+			errs = append(errs, fmt.Errorf("parameter '%s' is immutable", name))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// FillPaaSParameterDefaults returns a copy of the parameters, completed with the default values
+// of the missing parameters of the schema.
+func FillPaaSParameterDefaults(schema map[string]Parameter, params map[string]interface{}) map[string]interface{} {
+	filled := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		filled[name] = value
+	}
+	for name, parameter := range schema {
+		if _, ok := filled[name]; !ok && parameter.Default != nil {
+			filled[name] = parameter.Default
+		}
+	}
+	return filled
+}
+
+// ValidatePaaSResourceLimits checks resource limits against a PaaS template: limits must be positive and unique,
+// "memory" and "connections" must not exceed the template's Resources, and "cores" and "storage" must be within
+// the template's Autoscaling range. Other resources are not checked.
+func ValidatePaaSResourceLimits(template PaaSTemplateProperties, limits []ResourceLimit) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, limit := range limits {
+		if seen[limit.Resource] {
+			errs = append(errs, fmt.Errorf("resource limit '%s' is duplicated", limit.Resource))
+			continue
+		}
+		seen[limit.Resource] = true
+		if limit.Limit <= 0 {
+			errs = append(errs, fmt.Errorf("resource limit '%s' must be positive", limit.Resource))
+			continue
+		}
+		var minLimit, maxLimit int
+		switch limit.Resource {
+		case "memory":
+			maxLimit = template.Resources.Memory
+		case "connections":
+			maxLimit = template.Resources.Connections
+		case "cores":
+			minLimit, maxLimit = template.Autoscaling.Cores.Min, template.Autoscaling.Cores.Max
+		case "storage":
+			minLimit, maxLimit = template.Autoscaling.Storage.Min, template.Autoscaling.Storage.Max
+		}
+		if limit.Limit < minLimit || (maxLimit > 0 && limit.Limit > maxLimit) {
+			errs = append(errs, fmt.Errorf("resource limit '%s' must be between %d and %d", limit.Resource, minLimit, maxLimit))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// ValidatePaaSServiceCreateRequest checks a request for creating a PaaS service against its template
+// (see ValidatePaaSParameters and ValidatePaaSResourceLimits), and returns the request with the default
+// parameters filled in. Use it before CreatePaaSService to avoid rejected requests.
+func (c *Client) ValidatePaaSServiceCreateRequest(ctx context.Context, body PaaSServiceCreateRequest) (PaaSServiceCreateRequest, error) {
+	template, err := c.findPaaSTemplate(ctx, body.PaaSServiceTemplateUUID)
+	if err != nil {
+		return body, err
+	}
+	body.Parameters = FillPaaSParameterDefaults(template.ParametersSchema, body.Parameters)
+	err = joinErrors([]error{
+		ValidatePaaSParameters(template.ParametersSchema, body.Parameters),
+		ValidatePaaSResourceLimits(template, body.ResourceLimits),
+	})
+	return body, err
+}
+
+// ValidatePaaSServiceUpdateRequest checks a request for updating a PaaS service against the template of the service,
+// or the new template if the request changes it (see ValidatePaaSParameterUpdate and ValidatePaaSResourceLimits).
+// Use it before UpdatePaaSService to protect immutable parameters.
+func (c *Client) ValidatePaaSServiceUpdateRequest(ctx context.Context, id string, body PaaSServiceUpdateRequest) error {
+	service, err := c.GetPaaSService(ctx, id)
+	if err != nil {
+		return err
+	}
+	templateUUID := service.Properties.ServiceTemplateUUID
+	if body.PaaSServiceTemplateUUID != "" {
+		templateUUID = body.PaaSServiceTemplateUUID
+	}
+	template, err := c.findPaaSTemplate(ctx, templateUUID)
+	if err != nil {
+		return err
+	}
+	return joinErrors([]error{
+		ValidatePaaSParameterUpdate(template.ParametersSchema, service.Properties.Parameters, body.Parameters),
+		ValidatePaaSResourceLimits(template, body.ResourceLimits),
+	})
+}
+
+// findPaaSTemplate returns the PaaS template with the given UUID.
+func (c *Client) findPaaSTemplate(ctx context.Context, id string) (PaaSTemplateProperties, error) {
+	if !isValidUUID(id) {
+		return PaaSTemplateProperties{}, errors.New("'PaaSServiceTemplateUUID' is invalid")
+	}
+	templates, err := c.GetPaaSTemplateList(ctx)
+	if err != nil {
+		return PaaSTemplateProperties{}, err
+	}
+	for _, template := range templates {
+		if template.Properties.ObjectUUID == id {
+			return template.Properties, nil
+		}
+	}
+	return PaaSTemplateProperties{}, fmt.Errorf("PaaS template %s not found", id)
+}
+
+// validatePaaSParameterValues checks the names and values of the given parameters against the schema.
+func validatePaaSParameterValues(schema map[string]Parameter, params map[string]interface{}) []error {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		parameter, ok := schema[name]
+		if !ok {
+			err := fmt.Errorf("parameter '%s' is unknown", name)
+			if suggestion := closestPaaSParameterName(schema, name); suggestion != "" {
+				err = fmt.Errorf("parameter '%s' is unknown, did you mean '%s'?", name, suggestion)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		err := validatePaaSParameterValue(parameter, params[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parameter '%s' is invalid: %v", name, err))
+		}
+	}
+	return errs
+}
+
+// validatePaaSParameterValue checks a single parameter value against its schema. A nil value is always valid.
+func validatePaaSParameterValue(parameter Parameter, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	switch parameter.Type {
+	case paasParameterTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string, got %T", value)
+		}
+		if s == "" && !parameter.Empty && parameter.Required {
+			return errors.New("must not be empty")
+		}
+		if parameter.Regex != "" {
+			matched, err := regexp.MatchString("^(?:"+parameter.Regex+")$", s)
+			if err != nil {
+				return fmt.Errorf("regex of the schema is invalid: %v", err)
+			}
+			if !matched {
+				return fmt.Errorf("must match %s", parameter.Regex)
+			}
+		}
+	case paasParameterTypeInteger, paasParameterTypeFloat, paasParameterTypeNumber:
+		n, ok := paasParameterNumber(value)
+		if !ok {
+			return fmt.Errorf("must be a number, got %T", value)
+		}
+		if parameter.Type == paasParameterTypeInteger && n != math.Trunc(n) {
+			return errors.New("must be an integer")
+		}
+		// A zero bound is not defined by the schema.
+		hasMin, hasMax := parameter.Min != 0, parameter.Max != 0
+		if hasMin && hasMax && (n < float64(parameter.Min) || n > float64(parameter.Max)) {
+			return fmt.Errorf("must be between %d and %d", parameter.Min, parameter.Max)
+		}
+		if hasMin && n < float64(parameter.Min) {
+			return fmt.Errorf("must be at least %d", parameter.Min)
+		}
+		if hasMax && n > float64(parameter.Max) {
+			return fmt.Errorf("must be at most %d", parameter.Max)
+		}
+	case paasParameterTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be a boolean, got %T", value)
+		}
+	case paasParameterTypeList:
+		if _, ok := value.([]interface{}); !ok {
+			if _, ok := value.([]string); !ok {
+				return fmt.Errorf("must be a list, got %T", value)
+			}
+		}
+	case paasParameterTypeDict:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("must be a dict, got %T", value)
+		}
+	}
+	if len(parameter.Allowed) > 0 {
+		for _, allowed := range parameter.Allowed {
+			if fmt.Sprint(value) == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v", parameter.Allowed)
+	}
+	return nil
+}
+
+// paasParameterNumber converts a numeric parameter value to float64.
+func paasParameterNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equalPaaSParameterValues compares parameter values, ignoring the difference between numeric types,
+// e.g. between an int and the float64 decoded from JSON.
+func equalPaaSParameterValues(a, b interface{}) bool {
+	aNumber, aOk := paasParameterNumber(a)
+	bNumber, bOk := paasParameterNumber(b)
+	if aOk && bOk {
+		return aNumber == bNumber
+	}
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
+// closestPaaSParameterName returns the schema parameter name closest to a misspelled name,
+// or an empty string if no name is close enough.
+func closestPaaSParameterName(schema map[string]Parameter, name string) string {
+	closest := ""
+	closestDistance := len(name)/3 + 1
+	for _, candidate := range sortedPaaSParameterNames(schema) {
+		distance := levenshteinDistance(name, candidate)
+		if distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+	return closest
+}
+
+// levenshteinDistance returns the edit distance between two strings.
+func levenshteinDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	previous := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		current := make([]int, len(br)+1)
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(br)]
+}
+
+// sortedPaaSParameterNames returns the parameter names of a schema in alphabetical order.
+func sortedPaaSParameterNames(schema map[string]Parameter) []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
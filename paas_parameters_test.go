@@ -0,0 +1,191 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMockPaaSParametersSchema() map[string]Parameter {
+	return map[string]Parameter{
+		"mysql_max_connections": {Type: "integer", Min: 10, Max: 1000, Default: 100},
+		"mysql_sql_mode":        {Type: "string", Allowed: []string{"STRICT", "ANSI"}},
+		"mysql_database":        {Type: "string", Required: true, Regex: "[a-z_]+", Immutable: true},
+		"mysql_query_cache":     {Type: "boolean"},
+		"mysql_buffer_ratio":    {Type: "float"},
+		"mysql_min_workers":     {Type: "integer", Min: 2},
+		"mysql_io_offset":       {Type: "integer", Max: 8},
+	}
+}
+
+func TestValidatePaaSParameters(t *testing.T) {
+	schema := getMockPaaSParametersSchema()
+	type testCase struct {
+		params map[string]interface{}
+		err    string
+	}
+	testCases := []testCase{
+		{params: map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": 200, "mysql_sql_mode": "ANSI"}},
+		{params: map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": float64(10), "mysql_buffer_ratio": 0.5}},
+		{params: map[string]interface{}{"mysql_database": "shop", "mysql_query_cache": true, "mysql_sql_mode": nil}},
+		{params: map[string]interface{}{"mysql_database": "shop", "mysql_min_workers": 64}},
+		{params: map[string]interface{}{"mysql_database": "shop", "mysql_buffer_ratio": -0.5, "mysql_io_offset": -3}},
+		{
+			params: map[string]interface{}{"mysql_database": "shop", "mysql_io_offset": 16},
+			err:    "parameter 'mysql_io_offset' is invalid: must be at most 8",
+		},
+		{
+			params: map[string]interface{}{"mysql_database": "shop", "mysql_min_workers": 1},
+			err:    "parameter 'mysql_min_workers' is invalid: must be at least 2",
+		},
+		{
+			params: map[string]interface{}{"mysql_max_conections": 200},
+			err:    "parameter 'mysql_database' is required; parameter 'mysql_max_conections' is unknown, did you mean 'mysql_max_connections'?",
+		},
+		{
+			params: map[string]interface{}{"mysql_database": "shop", "foo": 1},
+			err:    "parameter 'foo' is unknown",
+		},
+		{
+			params: map[string]interface{}{"mysql_database": "Shop-1", "mysql_max_connections": 5000},
+			err:    "parameter 'mysql_database' is invalid: must match [a-z_]+; parameter 'mysql_max_connections' is invalid: must be between 10 and 1000",
+		},
+		{
+			params: map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": 10.5, "mysql_query_cache": "yes"},
+			err:    "parameter 'mysql_max_connections' is invalid: must be an integer; parameter 'mysql_query_cache' is invalid: must be a boolean, got string",
+		},
+		{
+			params: map[string]interface{}{"mysql_database": "shop", "mysql_sql_mode": "TRADITIONAL"},
+			err:    "parameter 'mysql_sql_mode' is invalid: must be one of [STRICT ANSI]",
+		},
+		{
+			params: map[string]interface{}{"mysql_database": ""},
+			err:    "parameter 'mysql_database' is invalid: must not be empty",
+		},
+	}
+	for _, test := range testCases {
+		err := ValidatePaaSParameters(schema, test.params)
+		if test.err == "" {
+			assert.Nil(t, err, "ValidatePaaSParameters returned an error %v", err)
+		} else if assert.NotNil(t, err) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+func TestValidatePaaSParameterUpdate(t *testing.T) {
+	schema := getMockPaaSParametersSchema()
+	current := map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": float64(100)}
+	err := ValidatePaaSParameterUpdate(schema, current, map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": 100})
+	assert.Nil(t, err, "ValidatePaaSParameterUpdate returned an error %v", err)
+	err = ValidatePaaSParameterUpdate(schema, current, map[string]interface{}{"mysql_max_connections": 200})
+	assert.Nil(t, err, "ValidatePaaSParameterUpdate returned an error %v", err)
+	err = ValidatePaaSParameterUpdate(schema, current, map[string]interface{}{"mysql_database": "blog"})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "parameter 'mysql_database' is immutable", err.Error())
+	}
+}
+
+func TestFillPaaSParameterDefaults(t *testing.T) {
+	params := map[string]interface{}{"mysql_database": "shop"}
+	filled := FillPaaSParameterDefaults(getMockPaaSParametersSchema(), params)
+	assert.Equal(t, map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": 100}, filled)
+	assert.Equal(t, 1, len(params))
+
+	filled = FillPaaSParameterDefaults(getMockPaaSParametersSchema(), map[string]interface{}{"mysql_max_connections": 50})
+	assert.Equal(t, 50, filled["mysql_max_connections"])
+}
+
+func TestValidatePaaSResourceLimits(t *testing.T) {
+	template := PaaSTemplateProperties{
+		Resources:   Resource{Memory: 4096},
+		Autoscaling: AutoscalingProperties{Cores: AutoscalingResourceProperties{Min: 1, Max: 8}},
+	}
+	err := ValidatePaaSResourceLimits(template, []ResourceLimit{{Resource: "memory", Limit: 2048}, {Resource: "cores", Limit: 4}, {Resource: "cpu", Limit: 2}})
+	assert.Nil(t, err, "ValidatePaaSResourceLimits returned an error %v", err)
+	err = ValidatePaaSResourceLimits(template, []ResourceLimit{
+		{Resource: "memory", Limit: 8192},
+		{Resource: "cores", Limit: 16},
+		{Resource: "cpu", Limit: 0},
+		{Resource: "cpu", Limit: 2},
+	})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "resource limit 'memory' must be between 0 and 4096; resource limit 'cores' must be between 1 and 8; "+
+			"resource limit 'cpu' must be positive; resource limit 'cpu' is duplicated", err.Error())
+	}
+}
+
+func TestClient_ValidatePaaSServiceCreateRequest(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	preparePaaSParametersTemplateHTTPGet(mux)
+	body, err := client.ValidatePaaSServiceCreateRequest(emptyCtx, PaaSServiceCreateRequest{
+		Name:                    "test",
+		PaaSServiceTemplateUUID: dummyUUID,
+		Parameters:              map[string]interface{}{"mysql_database": "shop"},
+	})
+	assert.Nil(t, err, "ValidatePaaSServiceCreateRequest returned an error %v", err)
+	assert.Equal(t, map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": float64(100)}, body.Parameters)
+
+	_, err = client.ValidatePaaSServiceCreateRequest(emptyCtx, PaaSServiceCreateRequest{
+		PaaSServiceTemplateUUID: dummyUUID,
+		ResourceLimits:          []ResourceLimit{{Resource: "memory", Limit: 8192}},
+	})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "parameter 'mysql_database' is required; resource limit 'memory' must be between 0 and 4096", err.Error())
+	}
+	_, err = client.ValidatePaaSServiceCreateRequest(emptyCtx, PaaSServiceCreateRequest{PaaSServiceTemplateUUID: "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"})
+	assert.NotNil(t, err)
+	_, err = client.ValidatePaaSServiceCreateRequest(emptyCtx, PaaSServiceCreateRequest{})
+	assert.NotNil(t, err)
+}
+
+func TestClient_ValidatePaaSServiceUpdateRequest(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	preparePaaSParametersTemplateHTTPGet(mux)
+	serviceUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", serviceUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(PaaSService{Properties: PaaSServiceProperties{
+			ObjectUUID:          serviceUUID,
+			ServiceTemplateUUID: dummyUUID,
+			Parameters:          map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": 100},
+		}})
+		fmt.Fprint(w, string(res))
+	})
+	err := client.ValidatePaaSServiceUpdateRequest(emptyCtx, serviceUUID, PaaSServiceUpdateRequest{
+		Parameters: map[string]interface{}{"mysql_database": "shop", "mysql_max_connections": 200},
+	})
+	assert.Nil(t, err, "ValidatePaaSServiceUpdateRequest returned an error %v", err)
+	err = client.ValidatePaaSServiceUpdateRequest(emptyCtx, serviceUUID, PaaSServiceUpdateRequest{
+		Parameters: map[string]interface{}{"mysql_database": "blog"},
+	})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "parameter 'mysql_database' is immutable", err.Error())
+	}
+	err = client.ValidatePaaSServiceUpdateRequest(emptyCtx, serviceUUID, PaaSServiceUpdateRequest{
+		PaaSServiceTemplateUUID: "4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d",
+	})
+	assert.NotNil(t, err)
+}
+
+func preparePaaSParametersTemplateHTTPGet(mux *http.ServeMux) {
+	mux.HandleFunc(path.Join(apiPaaSBase, "service_templates"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(PaaSTemplates{List: map[string]PaaSTemplateProperties{
+			dummyUUID: {
+				ObjectUUID:       dummyUUID,
+				Name:             "MySQL 8.0",
+				Resources:        Resource{Memory: 4096},
+				ParametersSchema: getMockPaaSParametersSchema(),
+			},
+		}})
+		fmt.Fprint(w, string(res))
+	})
+}
@@ -0,0 +1,184 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// PaaSUpgradeKind is the kind of a PaaS template change.
+type PaaSUpgradeKind string
+
+// All available PaaS upgrade kinds.
+const (
+	// PaaSPatchUpdate changes to a template of PaaSTemplateProperties.PatchUpdates.
+	PaaSPatchUpdate PaaSUpgradeKind = "patch"
+
+	// PaaSVersionUpgrade changes to a template of PaaSTemplateProperties.VersionUpgrades.
+	PaaSVersionUpgrade PaaSUpgradeKind = "version"
+
+	// PaaSPerformanceClassUpdate changes to a template of PaaSTemplateProperties.PerformanceClassUpdates.
+	PaaSPerformanceClassUpdate PaaSUpgradeKind = "performance_class"
+)
+
+// PaaSUpgradeStep represents a single template change of a PaaS service.
+type PaaSUpgradeStep struct {
+	// Kind of the change.
+	Kind PaaSUpgradeKind
+
+	// The UUID of the template after the change.
+	TemplateUUID string
+
+	// Name of the template after the change.
+	Name string
+
+	// Version of the service after the change.
+	Version string
+
+	// Release of the service after the change.
+	Release string
+
+	// Performance class of the service after the change.
+	PerformanceClass string
+}
+
+// PaaSUpgradePath represents the template changes needed to reach a target template.
+type PaaSUpgradePath struct {
+	// The changes in order of execution. The last step reaches the target template.
+	Steps []PaaSUpgradeStep
+}
+
+// Target returns the last step of the path.
+func (p PaaSUpgradePath) Target() PaaSUpgradeStep {
+	if len(p.Steps) == 0 {
+		return PaaSUpgradeStep{}
+	}
+	return p.Steps[len(p.Steps)-1]
+}
+
+// PlanPaaSUpgrades returns the shortest upgrade path to every template reachable from a template
+// by patch updates, version upgrades and performance class updates, sorted by the number of steps and
+// the name of the target template. Templates missing in the given list are not reachable.
+func PlanPaaSUpgrades(templates []PaaSTemplate, fromTemplateUUID string) ([]PaaSUpgradePath, error) {
+	byUUID := make(map[string]PaaSTemplateProperties, len(templates))
+	for _, template := range templates {
+		byUUID[template.Properties.ObjectUUID] = template.Properties
+	}
+	if _, ok := byUUID[fromTemplateUUID]; !ok {
+		return nil, fmt.Errorf("PaaS template %s not found", fromTemplateUUID)
+	}
+	paths := map[string][]PaaSUpgradeStep{fromTemplateUUID: nil}
+	queue := []string{fromTemplateUUID}
+	var reached []string
+	for len(queue) > 0 {
+		current := byUUID[queue[0]]
+		queue = queue[1:]
+		changes := []struct {
+			kind  PaaSUpgradeKind
+			uuids []string
+		}{
+			{PaaSPatchUpdate, current.PatchUpdates},
+			{PaaSVersionUpgrade, current.VersionUpgrades},
+			{PaaSPerformanceClassUpdate, current.PerformanceClassUpdates},
+		}
+		for _, change := range changes {
+			for _, uuid := range change.uuids {
+				target, ok := byUUID[uuid]
+				if _, visited := paths[uuid]; visited || !ok {
+					continue
+				}
+				steps := make([]PaaSUpgradeStep, len(paths[current.ObjectUUID]), len(paths[current.ObjectUUID])+1)
+				copy(steps, paths[current.ObjectUUID])
+				paths[uuid] = append(steps, PaaSUpgradeStep{
+					Kind:             change.kind,
+					TemplateUUID:     uuid,
+					Name:             target.Name,
+					Version:          target.Version,
+					Release:          target.Release,
+					PerformanceClass: target.PerformanceClass,
+				})
+				queue = append(queue, uuid)
+				reached = append(reached, uuid)
+			}
+		}
+	}
+	upgradePaths := make([]PaaSUpgradePath, 0, len(reached))
+	for _, uuid := range reached {
+		upgradePaths = append(upgradePaths, PaaSUpgradePath{Steps: paths[uuid]})
+	}
+	sort.SliceStable(upgradePaths, func(i, j int) bool {
+		if len(upgradePaths[i].Steps) != len(upgradePaths[j].Steps) {
+			return len(upgradePaths[i].Steps) < len(upgradePaths[j].Steps)
+		}
+		return upgradePaths[i].Target().Name < upgradePaths[j].Target().Name
+	})
+	return upgradePaths, nil
+}
+
+// GetPaaSUpgradePaths returns the upgrade paths of a PaaS service to all reachable templates, see PlanPaaSUpgrades.
+func (c *Client) GetPaaSUpgradePaths(ctx context.Context, id string) ([]PaaSUpgradePath, error) {
+	service, err := c.GetPaaSService(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := c.GetPaaSTemplateList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return PlanPaaSUpgrades(templates, service.Properties.ServiceTemplateUUID)
+}
+
+// UpgradePaaSService changes the template of a PaaS service to the target template along the shortest
+// upgrade path. The service is updated with one step after the other, waiting for the active status
+// before the first step, and after each step until the service runs on the template of the step.
+// The executed path is returned.
+func (c *Client) UpgradePaaSService(ctx context.Context, id, targetTemplateUUID string) (PaaSUpgradePath, error) {
+	if !isValidUUID(targetTemplateUUID) {
+		return PaaSUpgradePath{}, errors.New("'targetTemplateUUID' is invalid")
+	}
+	paths, err := c.GetPaaSUpgradePaths(ctx, id)
+	if err != nil {
+		return PaaSUpgradePath{}, err
+	}
+	var upgradePath PaaSUpgradePath
+	for _, path := range paths {
+		if path.Target().TemplateUUID == targetTemplateUUID {
+			upgradePath = path
+			break
+		}
+	}
+	if len(upgradePath.Steps) == 0 {
+		return upgradePath, fmt.Errorf("PaaS template %s is not reachable by an upgrade", targetTemplateUUID)
+	}
+	_, err = c.waitForPaaSServiceActive(ctx, id)
+	if err != nil {
+		return upgradePath, err
+	}
+	for i, step := range upgradePath.Steps {
+		err = c.UpdatePaaSService(ctx, id, PaaSServiceUpdateRequest{PaaSServiceTemplateUUID: step.TemplateUUID})
+		if err != nil {
+			return upgradePath, fmt.Errorf("upgrade step %d to %s failed: %v", i+1, step.Name, err)
+		}
+		// The service may still report the previous template as active right after the update.
+		templateUUID := step.TemplateUUID
+		_, err = waitForResource(ctx, c.DelayInterval(), func() (PaaSService, error) {
+			return c.GetPaaSService(ctx, id)
+		}, func(service PaaSService) bool {
+			return service.Properties.ServiceTemplateUUID == templateUUID && service.Properties.Status == resourceActiveStatus
+		})
+		if err != nil {
+			return upgradePath, fmt.Errorf("waiting for upgrade step %d to %s failed: %v", i+1, step.Name, err)
+		}
+	}
+	return upgradePath, nil
+}
+
+// waitForPaaSServiceActive waits until a PaaS service is active and returns it.
+func (c *Client) waitForPaaSServiceActive(ctx context.Context, id string) (PaaSService, error) {
+	return waitForResource(ctx, c.DelayInterval(), func() (PaaSService, error) {
+		return c.GetPaaSService(ctx, id)
+	}, func(service PaaSService) bool {
+		return service.Properties.Status == resourceActiveStatus
+	})
+}
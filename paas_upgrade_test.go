@@ -0,0 +1,138 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dummyPostgres13UUID     = "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	dummyPostgres131UUID    = "4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d"
+	dummyPostgres14UUID     = "5c6d9b4e-3f4a-4b7c-8d1e-2f3a4b5c6d7e"
+	dummyPostgres14HighUUID = "6d7e0c5f-4a5b-4c8d-9e2f-3a4b5c6d7e8f"
+	dummyPostgres15UUID     = "7e8f1d6a-5b6c-4d9e-8f3a-4b5c6d7e8f9a"
+)
+
+func getMockPaaSUpgradeTemplates() []PaaSTemplate {
+	return []PaaSTemplate{
+		{Properties: PaaSTemplateProperties{
+			ObjectUUID:      dummyPostgres13UUID,
+			Name:            "PostgreSQL 13",
+			Version:         "13",
+			PatchUpdates:    []string{dummyPostgres131UUID},
+			VersionUpgrades: []string{dummyPostgres14UUID, dummyUUID},
+		}},
+		{Properties: PaaSTemplateProperties{
+			ObjectUUID:      dummyPostgres131UUID,
+			Name:            "PostgreSQL 13.1",
+			Version:         "13",
+			Release:         "13.1",
+			VersionUpgrades: []string{dummyPostgres14UUID},
+		}},
+		{Properties: PaaSTemplateProperties{
+			ObjectUUID:              dummyPostgres14UUID,
+			Name:                    "PostgreSQL 14",
+			Version:                 "14",
+			VersionUpgrades:         []string{dummyPostgres15UUID},
+			PerformanceClassUpdates: []string{dummyPostgres14HighUUID},
+		}},
+		{Properties: PaaSTemplateProperties{
+			ObjectUUID:       dummyPostgres14HighUUID,
+			Name:             "PostgreSQL 14 high",
+			Version:          "14",
+			PerformanceClass: "high",
+		}},
+		{Properties: PaaSTemplateProperties{
+			ObjectUUID:      dummyPostgres15UUID,
+			Name:            "PostgreSQL 15",
+			Version:         "15",
+			VersionUpgrades: []string{dummyPostgres13UUID},
+		}},
+	}
+}
+
+func TestPlanPaaSUpgrades(t *testing.T) {
+	paths, err := PlanPaaSUpgrades(getMockPaaSUpgradeTemplates(), dummyPostgres13UUID)
+	assert.Nil(t, err, "PlanPaaSUpgrades returned an error %v", err)
+	var targets []string
+	for _, path := range paths {
+		targets = append(targets, fmt.Sprintf("%s (%d)", path.Target().Name, len(path.Steps)))
+	}
+	assert.Equal(t, []string{"PostgreSQL 13.1 (1)", "PostgreSQL 14 (1)", "PostgreSQL 14 high (2)", "PostgreSQL 15 (2)"}, targets)
+	assert.Equal(t, []PaaSUpgradeStep{
+		{Kind: PaaSVersionUpgrade, TemplateUUID: dummyPostgres14UUID, Name: "PostgreSQL 14", Version: "14"},
+		{Kind: PaaSPerformanceClassUpdate, TemplateUUID: dummyPostgres14HighUUID, Name: "PostgreSQL 14 high", Version: "14", PerformanceClass: "high"},
+	}, paths[2].Steps)
+	assert.Equal(t, PaaSPatchUpdate, paths[0].Steps[0].Kind)
+
+	paths, err = PlanPaaSUpgrades(getMockPaaSUpgradeTemplates(), dummyPostgres14HighUUID)
+	assert.Nil(t, err, "PlanPaaSUpgrades returned an error %v", err)
+	assert.Empty(t, paths)
+
+	_, err = PlanPaaSUpgrades(getMockPaaSUpgradeTemplates(), dummyUUID)
+	assert.NotNil(t, err)
+	assert.Equal(t, PaaSUpgradeStep{}, PaaSUpgradePath{}.Target())
+}
+
+func TestClient_UpgradePaaSService(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	mux.HandleFunc(path.Join(apiPaaSBase, "service_templates"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		templates := PaaSTemplates{List: make(map[string]PaaSTemplateProperties)}
+		for _, template := range getMockPaaSUpgradeTemplates() {
+			templates.List[template.Properties.ObjectUUID] = template.Properties
+		}
+		res, _ := json.Marshal(templates)
+		fmt.Fprint(w, string(res))
+	})
+	currentTemplateUUID := dummyPostgres13UUID
+	var pendingTemplateUUID string
+	var staleGets int
+	var upgrades []string
+	mux.HandleFunc(path.Join(apiPaaSBase, "services", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		switch r.Method {
+		case http.MethodGet:
+			res, _ := json.Marshal(PaaSService{Properties: PaaSServiceProperties{
+				ObjectUUID:          dummyUUID,
+				ServiceTemplateUUID: currentTemplateUUID,
+				Status:              resourceActiveStatus,
+			}})
+			fmt.Fprint(w, string(res))
+			// The service still reports the old template as active for the first GETs after an update.
+			if pendingTemplateUUID != "" {
+				staleGets--
+				if staleGets == 0 {
+					currentTemplateUUID, pendingTemplateUUID = pendingTemplateUUID, ""
+				}
+			}
+		case http.MethodPatch:
+			var body PaaSServiceUpdateRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Empty(t, pendingTemplateUUID, "the previous upgrade step is still running")
+			pendingTemplateUUID, staleGets = body.PaaSServiceTemplateUUID, 2
+			upgrades = append(upgrades, body.PaaSServiceTemplateUUID)
+		}
+	})
+
+	paths, err := client.GetPaaSUpgradePaths(emptyCtx, dummyUUID)
+	assert.Nil(t, err, "GetPaaSUpgradePaths returned an error %v", err)
+	assert.Equal(t, 4, len(paths))
+
+	upgradePath, err := client.UpgradePaaSService(emptyCtx, dummyUUID, dummyPostgres15UUID)
+	assert.Nil(t, err, "UpgradePaaSService returned an error %v", err)
+	assert.Equal(t, 2, len(upgradePath.Steps))
+	assert.Equal(t, []string{dummyPostgres14UUID, dummyPostgres15UUID}, upgrades)
+	assert.Equal(t, dummyPostgres15UUID, currentTemplateUUID)
+
+	_, err = client.UpgradePaaSService(emptyCtx, dummyUUID, dummyPostgres15UUID)
+	assert.NotNil(t, err)
+	_, err = client.UpgradePaaSService(emptyCtx, dummyUUID, "invalid")
+	assert.NotNil(t, err)
+}
@@ -22,6 +22,7 @@ type ServerOperator interface {
 	GetServerMetricList(ctx context.Context, id string) ([]ServerMetric, error)
 	GetServerEventList(ctx context.Context, id string) ([]Event, error)
 	GetDeletedServers(ctx context.Context) ([]Server, error)
+	AnalyzeServerRightsizing(ctx context.Context, opts ServerRightsizingOptions) (ServerRightsizingReport, error)
 }
 
 // ServerList holds a list of servers.
@@ -166,7 +167,7 @@ type ServerCreateRequest struct {
 	// **Caution**: This field is deprecated.
 	Relations *ServerCreateRequestRelations `json:"relations,omitempty"`
 
-	// For system configuration on first boot. May contain cloud-config data or shell scripting, encoded as base64 string. Supported tools are cloud-init, Cloudbase-init, and Ignition.
+	// For system configuration on first boot. May contain cloud-config data or shell scripting, encoded as base64 string. Supported tools are cloud-init, Cloudbase-init, and Ignition. Use EncodeUserData to validate and encode it.
 	UserData *string `json:"user_data,omitempty"`
 }
 
@@ -273,7 +274,7 @@ type ServerUpdateRequest struct {
 	// Note: hardware_profile and hardware_profile_config parameters can't be used at the same time.
 	HardwareProfileConfig *ServerHardwareProfileConfig `json:"hardware_profile_config,omitempty"`
 
-	// For system configuration on first boot. May contain cloud-config data or shell scripting, encoded as base64 string. Supported tools are cloud-init, Cloudbase-init, and Ignition.
+	// For system configuration on first boot. May contain cloud-config data or shell scripting, encoded as base64 string. Supported tools are cloud-init, Cloudbase-init, and Ignition. Use EncodeUserData to validate and encode it.
 	UserData *string `json:"user_data,omitempty"`
 }
 
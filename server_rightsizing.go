@@ -0,0 +1,274 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// hoursPerMonth is the average number of hours in a month, used to extrapolate savings.
+const hoursPerMonth = 730
+
+// coreUsagePercentageUnit is the unit of the core usage of server metrics used for rightsizing.
+// Metrics with other units are skipped.
+const coreUsagePercentageUnit = "percentage"
+
+// Defaults of ServerRightsizingOptions.
+const (
+	defaultRightsizingWindow = 30 * 24 * time.Hour
+	defaultTargetUtilization = 0.6
+	defaultLowUtilization    = 0.2
+	defaultHighUtilization   = 0.8
+)
+
+// ServerRightsizingAction represents the action recommended for a server.
+type ServerRightsizingAction string
+
+// All available server rightsizing actions.
+const (
+	KeepServerSize   ServerRightsizingAction = "keep"
+	UpsizeServer     ServerRightsizingAction = "upsize"
+	DownsizeServer   ServerRightsizingAction = "downsize"
+	InsufficientData ServerRightsizingAction = "insufficient-data"
+)
+
+// ServerRightsizingOptions represents the options of a server rightsizing analysis.
+type ServerRightsizingOptions struct {
+	// The time window of the observed utilisation. Default: 30 days.
+	Window time.Duration
+
+	// The utilisation (0-1) a resized server should have at its peak. Default: 0.6.
+	TargetUtilization float64
+
+	// Servers whose peak utilisation (0-1) is below this value are downsized. Default: 0.2.
+	LowUtilization float64
+
+	// Servers whose peak utilisation (0-1) is above this value are upsized. Default: 0.8.
+	HighUtilization float64
+
+	// Price of a core per hour, used to estimate savings. Optional.
+	CorePricePerHour float64
+
+	// Price of a GB of memory per hour, used to estimate savings. Optional.
+	MemoryPricePerGBHour float64
+
+	// Returns the peak memory utilisation (0-1) of a server, e.g. from a monitoring system,
+	// as the API does not report it. Leave it nil to keep the memory of all servers.
+	MemoryUtilization func(ctx context.Context, server Server) (float64, error)
+
+	// If true, the recommendations are applied. Running servers are power-cycled if their hardware
+	// does not support hot-plugging (legacy hardware) or if they are downsized.
+	Apply bool
+}
+
+// ServerRightsizingRecommendation represents the recommendation for a single server.
+type ServerRightsizingRecommendation struct {
+	// The UUID of the server.
+	ServerUUID string
+
+	// The name of the server.
+	ServerName string
+
+	// The recommended action.
+	Action ServerRightsizingAction
+
+	// The configured number of cores.
+	CurrentCores int
+
+	// The recommended number of cores.
+	RecommendedCores int
+
+	// The configured memory in GB.
+	CurrentMemory int
+
+	// The recommended memory in GB.
+	RecommendedMemory int
+
+	// The average core utilisation in percent.
+	AverageCoreUtilization float64
+
+	// The peak (95th percentile) core utilisation in percent.
+	PeakCoreUtilization float64
+
+	// The peak memory utilisation in percent, or -1 if unknown.
+	PeakMemoryUtilization float64
+
+	// Estimated savings per month, extrapolated from the time the server was running within the window.
+	// Negative for upsized servers.
+	EstimatedMonthlySavings float64
+
+	// True if the recommendation was applied.
+	Applied bool
+}
+
+// ServerRightsizingReport represents the result of a server rightsizing analysis.
+type ServerRightsizingReport struct {
+	// The time of the analysis.
+	AnalysisTime time.Time
+
+	// The analysed time window.
+	Window time.Duration
+
+	// The recommendations, sorted by server name.
+	Recommendations []ServerRightsizingRecommendation
+
+	// The sum of the estimated monthly savings.
+	TotalMonthlySavings float64
+}
+
+// AnalyzeServerRightsizing compares the configured cores and memory of all servers with their observed
+// utilisation and recommends up- or downsizing them. The core utilisation is taken from the server metrics.
+// The running time used to extrapolate savings is the time covered by the metrics within the window:
+// the usage minutes of a server (UsageInMinutesCores) are summed over its whole lifetime, and the usage API
+// reports usage per product rather than per server and window.
+func (c *Client) AnalyzeServerRightsizing(ctx context.Context, opts ServerRightsizingOptions) (ServerRightsizingReport, error) {
+	opts = withServerRightsizingDefaults(opts)
+	if opts.LowUtilization >= opts.TargetUtilization || opts.TargetUtilization >= opts.HighUtilization || opts.HighUtilization > 1 {
+		return ServerRightsizingReport{}, errors.New("utilisations must satisfy 0 < 'LowUtilization' < 'TargetUtilization' < 'HighUtilization' <= 1")
+	}
+	report := ServerRightsizingReport{
+		AnalysisTime: time.Now().UTC(),
+		Window:       opts.Window,
+	}
+	servers, err := c.GetServerList(ctx)
+	if err != nil {
+		return report, err
+	}
+	sort.SliceStable(servers, func(i, j int) bool {
+		return servers[i].Properties.Name < servers[j].Properties.Name
+	})
+	for _, server := range servers {
+		metrics, err := c.GetServerMetricList(ctx, server.Properties.ObjectUUID)
+		if err != nil {
+			return report, err
+		}
+		memoryUtilization := -1.0
+		if opts.MemoryUtilization != nil {
+			memoryUtilization, err = opts.MemoryUtilization(ctx, server)
+			if err != nil {
+				return report, fmt.Errorf("getting memory utilisation of server %s failed: %v", server.Properties.Name, err)
+			}
+		}
+		recommendation := recommendServerSize(server, metrics, memoryUtilization, opts, report.AnalysisTime)
+		if opts.Apply && (recommendation.Action == UpsizeServer || recommendation.Action == DownsizeServer) {
+			err = c.resizeServer(ctx, server, recommendation)
+			if err != nil {
+				return report, fmt.Errorf("resizing server %s failed: %v", server.Properties.Name, err)
+			}
+			recommendation.Applied = true
+		}
+		report.Recommendations = append(report.Recommendations, recommendation)
+		report.TotalMonthlySavings += recommendation.EstimatedMonthlySavings
+	}
+	return report, nil
+}
+
+// withServerRightsizingDefaults sets the defaults of unset options.
+func withServerRightsizingDefaults(opts ServerRightsizingOptions) ServerRightsizingOptions {
+	if opts.Window == 0 {
+		opts.Window = defaultRightsizingWindow
+	}
+	if opts.TargetUtilization == 0 {
+		opts.TargetUtilization = defaultTargetUtilization
+	}
+	if opts.LowUtilization == 0 {
+		opts.LowUtilization = defaultLowUtilization
+	}
+	if opts.HighUtilization == 0 {
+		opts.HighUtilization = defaultHighUtilization
+	}
+	return opts
+}
+
+// recommendServerSize recommends the size of a server from its core usage samples in percent within the window
+// and its peak memory utilisation (0-1, negative if unknown).
+func recommendServerSize(server Server, metrics []ServerMetric, memoryUtilization float64, opts ServerRightsizingOptions, now time.Time) ServerRightsizingRecommendation {
+	properties := server.Properties
+	recommendation := ServerRightsizingRecommendation{
+		ServerUUID:            properties.ObjectUUID,
+		ServerName:            properties.Name,
+		Action:                InsufficientData,
+		CurrentCores:          properties.Cores,
+		RecommendedCores:      properties.Cores,
+		CurrentMemory:         properties.Memory,
+		RecommendedMemory:     properties.Memory,
+		PeakMemoryUtilization: -1,
+	}
+	windowStart := now.Add(-opts.Window)
+	var samples []float64
+	var runtime time.Duration
+	for _, metric := range metrics {
+		if metric.Properties.EndTime.Before(windowStart) || metric.Properties.CoreUsage.Unit != coreUsagePercentageUnit {
+			continue
+		}
+		samples = append(samples, metric.Properties.CoreUsage.Value)
+		runtime += metric.Properties.EndTime.Sub(metric.Properties.BeginTime.Time)
+	}
+	if len(samples) == 0 {
+		return recommendation
+	}
+	sort.Float64s(samples)
+	var sum float64
+	for _, sample := range samples {
+		sum += sample
+	}
+	recommendation.AverageCoreUtilization = sum / float64(len(samples))
+	recommendation.PeakCoreUtilization = samples[int(math.Ceil(0.95*float64(len(samples))))-1]
+	recommendation.RecommendedCores = recommendServerResource(properties.Cores, recommendation.PeakCoreUtilization/100, opts)
+	if memoryUtilization >= 0 {
+		recommendation.PeakMemoryUtilization = memoryUtilization * 100
+		recommendation.RecommendedMemory = recommendServerResource(properties.Memory, memoryUtilization, opts)
+	}
+
+	coreDelta := recommendation.CurrentCores - recommendation.RecommendedCores
+	memoryDelta := recommendation.CurrentMemory - recommendation.RecommendedMemory
+	switch {
+	case coreDelta < 0 || memoryDelta < 0:
+		recommendation.Action = UpsizeServer
+	case coreDelta > 0 || memoryDelta > 0:
+		recommendation.Action = DownsizeServer
+	default:
+		recommendation.Action = KeepServerSize
+	}
+	runtimeShare := math.Min(runtime.Hours()/opts.Window.Hours(), 1)
+	hourlySavings := float64(coreDelta)*opts.CorePricePerHour + float64(memoryDelta)*opts.MemoryPricePerGBHour
+	recommendation.EstimatedMonthlySavings = hourlySavings * hoursPerMonth * runtimeShare
+	return recommendation
+}
+
+// recommendServerResource returns the recommended amount of a resource (cores or GB of memory)
+// for its peak utilisation (0-1). The amount is only changed if the utilisation is outside of the low/high band.
+func recommendServerResource(current int, utilization float64, opts ServerRightsizingOptions) int {
+	if utilization >= opts.LowUtilization && utilization <= opts.HighUtilization {
+		return current
+	}
+	recommended := int(math.Ceil(float64(current) * utilization / opts.TargetUtilization))
+	if recommended < 1 {
+		return 1
+	}
+	return recommended
+}
+
+// resizeServer applies a rightsizing recommendation. A running server is stopped during the update
+// if it cannot hot-plug the change, i.e. it has legacy hardware or loses cores or memory, and started again afterwards.
+func (c *Client) resizeServer(ctx context.Context, server Server, recommendation ServerRightsizingRecommendation) error {
+	isShrinking := recommendation.RecommendedCores < recommendation.CurrentCores || recommendation.RecommendedMemory < recommendation.CurrentMemory
+	powerCycle := server.Properties.Power && (server.Properties.Legacy || isShrinking)
+	if powerCycle {
+		err := c.powerOffServer(ctx, server.Properties.ObjectUUID)
+		if err != nil {
+			return err
+		}
+	}
+	err := c.UpdateServer(ctx, server.Properties.ObjectUUID, ServerUpdateRequest{
+		Cores:  recommendation.RecommendedCores,
+		Memory: recommendation.RecommendedMemory,
+	})
+	if powerCycle {
+		return joinErrors([]error{err, c.startServers(ctx, []string{server.Properties.ObjectUUID})})
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendServerSize(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	server := Server{Properties: ServerProperties{ObjectUUID: dummyUUID, Name: "test", Cores: 4, Memory: 8}}
+	opts := withServerRightsizingDefaults(ServerRightsizingOptions{
+		Window:               10 * time.Hour,
+		CorePricePerHour:     0.01,
+		MemoryPricePerGBHour: 0.005,
+	})
+	type testCase struct {
+		coreUsage         float64
+		hours             int
+		memoryUtilization float64
+		action            ServerRightsizingAction
+		cores             int
+		memory            int
+		savings           float64
+	}
+	testCases := []testCase{
+		{coreUsage: 10, hours: 10, memoryUtilization: -1, action: DownsizeServer, cores: 1, memory: 8, savings: 3 * 0.01 * hoursPerMonth},
+		{coreUsage: 10, hours: 5, memoryUtilization: 0.1, action: DownsizeServer, cores: 1, memory: 2, savings: (3*0.01 + 6*0.005) * hoursPerMonth / 2},
+		{coreUsage: 50, hours: 10, memoryUtilization: 0.5, action: KeepServerSize, cores: 4, memory: 8},
+		{coreUsage: 95, hours: 10, memoryUtilization: -1, action: UpsizeServer, cores: 7, memory: 8, savings: -3 * 0.01 * hoursPerMonth},
+		{coreUsage: 50, hours: 10, memoryUtilization: 0.9, action: UpsizeServer, cores: 4, memory: 12, savings: -4 * 0.005 * hoursPerMonth},
+		{coreUsage: 10, hours: 0, memoryUtilization: -1, action: InsufficientData, cores: 4, memory: 8},
+	}
+	for _, test := range testCases {
+		metrics := getMockRightsizingMetrics(now, test.hours, test.coreUsage)
+		// Samples outside of the window or with another unit are ignored.
+		metrics = append(metrics, getMockRightsizingMetrics(now.Add(-24*time.Hour), 1, 100)...)
+		otherUnit := getMockRightsizingMetrics(now, 1, 4)
+		otherUnit[0].Properties.CoreUsage.Unit = "cores"
+		metrics = append(metrics, otherUnit...)
+		recommendation := recommendServerSize(server, metrics, test.memoryUtilization, opts, now)
+		assert.Equal(t, test.action, recommendation.Action)
+		assert.Equal(t, test.cores, recommendation.RecommendedCores)
+		assert.Equal(t, test.memory, recommendation.RecommendedMemory)
+		assert.InDelta(t, test.savings, recommendation.EstimatedMonthlySavings, 0.0001)
+	}
+}
+
+func TestClient_AnalyzeServerRightsizing(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	power := true
+	var events []string
+	mux.HandleFunc(apiServerBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareServerListHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID, "metrics"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		var list ServerMetricList
+		for _, metric := range getMockRightsizingMetrics(time.Now(), 24, 10) {
+			list.List = append(list.List, metric.Properties)
+		}
+		res, _ := json.Marshal(list)
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodPatch {
+			var body ServerUpdateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			events = append(events, fmt.Sprintf("update %d cores, %d GB (power %v)", body.Cores, body.Memory, power))
+			return
+		}
+		fmt.Fprint(w, prepareServerHTTPGet(power, "active"))
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID, "shutdown"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		power = false
+		events = append(events, "shutdown")
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID, "power"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		var body ServerPowerUpdateRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		power = body.Power
+		events = append(events, fmt.Sprintf("power %v", power))
+	})
+	opts := ServerRightsizingOptions{
+		Window:           48 * time.Hour,
+		CorePricePerHour: 0.01,
+		MemoryUtilization: func(ctx context.Context, server Server) (float64, error) {
+			return 0.5, nil
+		},
+	}
+	report, err := client.AnalyzeServerRightsizing(emptyCtx, opts)
+	assert.Nil(t, err, "AnalyzeServerRightsizing returned an error %v", err)
+	assert.Equal(t, 1, len(report.Recommendations))
+	recommendation := report.Recommendations[0]
+	assert.Equal(t, DownsizeServer, recommendation.Action)
+	assert.Equal(t, 1, recommendation.RecommendedCores)
+	assert.Equal(t, 2, recommendation.RecommendedMemory)
+	assert.Equal(t, 50.0, recommendation.PeakMemoryUtilization)
+	assert.InDelta(t, 3*0.01*hoursPerMonth/2, report.TotalMonthlySavings, 0.0001)
+	assert.False(t, recommendation.Applied)
+	assert.Nil(t, events)
+
+	opts.Apply = true
+	report, err = client.AnalyzeServerRightsizing(emptyCtx, opts)
+	assert.Nil(t, err, "AnalyzeServerRightsizing returned an error %v", err)
+	assert.True(t, report.Recommendations[0].Applied)
+	assert.Equal(t, []string{"shutdown", "update 1 cores, 2 GB (power false)", "power true"}, events)
+
+	_, err = client.AnalyzeServerRightsizing(emptyCtx, ServerRightsizingOptions{LowUtilization: 0.7})
+	assert.NotNil(t, err)
+}
+
+// getMockRightsizingMetrics returns hourly core usage samples ending at the given time.
+func getMockRightsizingMetrics(end time.Time, hours int, coreUsage float64) []ServerMetric {
+	var metrics []ServerMetric
+	for i := 0; i < hours; i++ {
+		metric := ServerMetric{Properties: ServerMetricProperties{
+			BeginTime: GSTime{end.Add(-time.Duration(i+1) * time.Hour)},
+			EndTime:   GSTime{end.Add(-time.Duration(i) * time.Hour)},
+		}}
+		metric.Properties.CoreUsage.Value = coreUsage
+		metric.Properties.CoreUsage.Unit = coreUsagePercentageUnit
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
@@ -0,0 +1,289 @@
+package gsclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Content types of the parts of multipart user data.
+const (
+	CloudConfigContentType   = "text/cloud-config"
+	ShellScriptContentType   = "text/x-shellscript"
+	CloudBoothookContentType = "text/cloud-boothook"
+)
+
+// Headers identifying the format of user data.
+const (
+	cloudConfigHeader          = "#cloud-config"
+	cloudbaseInitScriptHeader  = "#ps1_sysnative"
+	multipartUserDataHeader    = "Content-Type: multipart/mixed"
+	defaultIgnitionSpecVersion = "3.3.0"
+)
+
+// CloudConfig represents cloud-config user data for cloud-init. Cloudbase-init supports
+// a subset of it (e.g. users, write_files and runcmd).
+type CloudConfig struct {
+	// Hostname of the server. Optional.
+	Hostname string `yaml:"hostname,omitempty"`
+
+	// Users to create. Optional.
+	Users []CloudConfigUser `yaml:"users,omitempty"`
+
+	// SSH public keys of the default user. Optional.
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+
+	// Update the package database on first boot. Optional.
+	PackageUpdate bool `yaml:"package_update,omitempty"`
+
+	// Packages to install. Optional.
+	Packages []string `yaml:"packages,omitempty"`
+
+	// Files to write. Optional.
+	WriteFiles []CloudConfigFile `yaml:"write_files,omitempty"`
+
+	// Commands to run on first boot. Optional.
+	RunCmd []string `yaml:"runcmd,omitempty"`
+}
+
+// CloudConfigUser represents a user of a cloud-config.
+type CloudConfigUser struct {
+	// Name of the user.
+	Name string `yaml:"name"`
+
+	// Comma-separated list of supplementary groups. Optional.
+	Groups string `yaml:"groups,omitempty"`
+
+	// Login shell, e.g. "/bin/bash". Optional.
+	Shell string `yaml:"shell,omitempty"`
+
+	// Sudo rule, e.g. "ALL=(ALL) NOPASSWD:ALL". Optional.
+	Sudo string `yaml:"sudo,omitempty"`
+
+	// SSH public keys of the user. Optional.
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+
+	// Disable password login of the user. Optional.
+	LockPasswd *bool `yaml:"lock_passwd,omitempty"`
+}
+
+// CloudConfigFile represents a file written by a cloud-config.
+type CloudConfigFile struct {
+	// Path of the file.
+	Path string `yaml:"path"`
+
+	// Content of the file.
+	Content string `yaml:"content"`
+
+	// Owner of the file, e.g. "root:root". Optional.
+	Owner string `yaml:"owner,omitempty"`
+
+	// Permissions of the file in octal notation, e.g. "0644". Optional.
+	Permissions string `yaml:"permissions,omitempty"`
+
+	// Append the content to an existing file. Optional.
+	Append bool `yaml:"append,omitempty"`
+}
+
+// Marshal returns the cloud-config as YAML document, starting with the "#cloud-config" header.
+func (cc CloudConfig) Marshal() ([]byte, error) {
+	for _, user := range cc.Users {
+		if user.Name == "" {
+			return nil, errors.New("user name is empty")
+		}
+	}
+	for _, file := range cc.WriteFiles {
+		if file.Path == "" {
+			return nil, errors.New("file path is empty")
+		}
+	}
+	content, err := yaml.Marshal(cc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(cloudConfigHeader+"\n"), content...), nil
+}
+
+// UserDataPart represents a part of multipart user data.
+type UserDataPart struct {
+	// Content type of the part, e.g. CloudConfigContentType or ShellScriptContentType.
+	ContentType string
+
+	// File name of the part. Optional.
+	Filename string
+
+	// Content of the part.
+	Content []byte
+}
+
+// BuildMultipartUserData combines several parts, e.g. a cloud-config and shell scripts,
+// into multipart MIME user data.
+func BuildMultipartUserData(parts ...UserDataPart) ([]byte, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("no user data parts given")
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range parts {
+		if part.ContentType == "" {
+			return nil, errors.New("content type of user data part is empty")
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("%s; charset=\"utf-8\"", part.ContentType))
+		header.Set("MIME-Version", "1.0")
+		if part.Filename != "" {
+			header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", part.Filename))
+		}
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		_, err = partWriter.Write(part.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("%s; boundary=%q\nMIME-Version: 1.0\n\n", multipartUserDataHeader, writer.Boundary())
+	return append([]byte(header), body.Bytes()...), nil
+}
+
+// IgnitionConfig represents Ignition user data, e.g. for Fedora CoreOS and Flatcar.
+type IgnitionConfig struct {
+	// Ignition metadata.
+	Ignition IgnitionMetadata `json:"ignition"`
+
+	// Users. Optional.
+	Passwd IgnitionPasswd `json:"passwd"`
+
+	// Files. Optional.
+	Storage IgnitionStorage `json:"storage"`
+}
+
+// IgnitionMetadata represents the metadata of an Ignition config.
+type IgnitionMetadata struct {
+	// Version of the Ignition spec. Leave it empty to use the default version 3.3.0.
+	Version string `json:"version"`
+}
+
+// IgnitionPasswd represents the users of an Ignition config.
+type IgnitionPasswd struct {
+	// Users to create or modify.
+	Users []IgnitionUser `json:"users,omitempty"`
+}
+
+// IgnitionUser represents a user of an Ignition config.
+type IgnitionUser struct {
+	// Name of the user, e.g. "core".
+	Name string `json:"name"`
+
+	// SSH public keys of the user. Optional.
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+
+	// Supplementary groups. Optional.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// IgnitionStorage represents the files of an Ignition config.
+type IgnitionStorage struct {
+	// Files to write.
+	Files []IgnitionFile `json:"files,omitempty"`
+}
+
+// IgnitionFile represents a file of an Ignition config.
+type IgnitionFile struct {
+	// Path of the file.
+	Path string `json:"path"`
+
+	// Permissions of the file as decimal number, e.g. 420 for 0644. Optional.
+	Mode *int `json:"mode,omitempty"`
+
+	// Content of the file.
+	Contents IgnitionFileContents `json:"contents"`
+}
+
+// IgnitionFileContents represents the content of a file of an Ignition config.
+type IgnitionFileContents struct {
+	// Source URL of the content. Use NewIgnitionFileContents to embed the content as data URL.
+	Source string `json:"source"`
+}
+
+// NewIgnitionFileContents returns file contents embedding the content as base64 data URL.
+func NewIgnitionFileContents(content []byte) IgnitionFileContents {
+	return IgnitionFileContents{Source: "data:;base64," + base64.StdEncoding.EncodeToString(content)}
+}
+
+// Marshal returns the Ignition config as JSON document.
+func (ic IgnitionConfig) Marshal() ([]byte, error) {
+	if ic.Ignition.Version == "" {
+		ic.Ignition.Version = defaultIgnitionSpecVersion
+	}
+	for _, user := range ic.Passwd.Users {
+		if user.Name == "" {
+			return nil, errors.New("user name is empty")
+		}
+	}
+	for _, file := range ic.Storage.Files {
+		if file.Path == "" {
+			return nil, errors.New("file path is empty")
+		}
+	}
+	return json.Marshal(ic)
+}
+
+// BuildCloudbaseInitScript returns user data running a PowerShell script with Cloudbase-init.
+func BuildCloudbaseInitScript(script string) []byte {
+	return []byte(cloudbaseInitScriptHeader + "\n" + script)
+}
+
+// ValidateUserData validates raw user data: it must not be empty, and it must be a cloud-config, a script
+// (e.g. shell or Cloudbase-init PowerShell), multipart MIME or an Ignition config. The size is left to the API.
+func ValidateUserData(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("user data is empty")
+	}
+	content := string(data)
+	switch {
+	case strings.HasPrefix(content, cloudConfigHeader):
+		var cloudConfig map[string]interface{}
+		err := yaml.Unmarshal(data, &cloudConfig)
+		if err != nil {
+			return fmt.Errorf("cloud-config is invalid: %v", err)
+		}
+		return nil
+	case strings.HasPrefix(content, "#!"), strings.HasPrefix(content, "#ps1"), strings.HasPrefix(content, multipartUserDataHeader):
+		return nil
+	case strings.HasPrefix(strings.TrimSpace(content), "{"):
+		var ignition IgnitionConfig
+		err := json.Unmarshal(data, &ignition)
+		if err != nil {
+			return fmt.Errorf("ignition config is invalid: %v", err)
+		}
+		if ignition.Ignition.Version == "" {
+			return errors.New("ignition config has no version")
+		}
+		return nil
+	}
+	return errors.New("user data format is unknown, expected cloud-config, script, multipart MIME or Ignition config")
+}
+
+// EncodeUserData validates raw user data (see ValidateUserData) and returns it base64 encoded,
+// as expected by ServerCreateRequest.UserData and ServerUpdateRequest.UserData.
+func EncodeUserData(data []byte) (*string, error) {
+	err := ValidateUserData(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	return &encoded, nil
+}
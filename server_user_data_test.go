@@ -0,0 +1,127 @@
+package gsclient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudConfig_Marshal(t *testing.T) {
+	lockPasswd := true
+	config := CloudConfig{
+		Hostname: "web",
+		Users: []CloudConfigUser{{
+			Name:              "deploy",
+			Sudo:              "ALL=(ALL) NOPASSWD:ALL",
+			SSHAuthorizedKeys: []string{"ssh-ed25519 AAAA deploy"},
+			LockPasswd:        &lockPasswd,
+		}},
+		Packages: []string{"nginx"},
+		WriteFiles: []CloudConfigFile{{
+			Path:        "/etc/motd",
+			Content:     "hello",
+			Permissions: "0644",
+		}},
+		RunCmd: []string{"systemctl enable --now nginx"},
+	}
+	data, err := config.Marshal()
+	assert.Nil(t, err, "Marshal returned an error %v", err)
+	expected := `#cloud-config
+hostname: web
+users:
+    - name: deploy
+      sudo: ALL=(ALL) NOPASSWD:ALL
+      ssh_authorized_keys:
+        - ssh-ed25519 AAAA deploy
+      lock_passwd: true
+packages:
+    - nginx
+write_files:
+    - path: /etc/motd
+      content: hello
+      permissions: "0644"
+runcmd:
+    - systemctl enable --now nginx
+`
+	assert.Equal(t, expected, string(data))
+	assert.Nil(t, ValidateUserData(data))
+
+	_, err = CloudConfig{Users: []CloudConfigUser{{Shell: "/bin/bash"}}}.Marshal()
+	assert.NotNil(t, err)
+	_, err = CloudConfig{WriteFiles: []CloudConfigFile{{Content: "hello"}}}.Marshal()
+	assert.NotNil(t, err)
+}
+
+func TestBuildMultipartUserData(t *testing.T) {
+	data, err := BuildMultipartUserData(
+		UserDataPart{ContentType: CloudConfigContentType, Content: []byte("#cloud-config\npackages: [nginx]\n")},
+		UserDataPart{ContentType: ShellScriptContentType, Filename: "setup.sh", Content: []byte("#!/bin/sh\necho hello\n")},
+	)
+	assert.Nil(t, err, "BuildMultipartUserData returned an error %v", err)
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "Content-Type: multipart/mixed; boundary="))
+	assert.Contains(t, content, "Content-Type: text/cloud-config; charset=\"utf-8\"")
+	assert.Contains(t, content, "Content-Disposition: attachment; filename=\"setup.sh\"")
+	assert.Contains(t, content, "echo hello")
+	assert.Nil(t, ValidateUserData(data))
+
+	_, err = BuildMultipartUserData()
+	assert.NotNil(t, err)
+	_, err = BuildMultipartUserData(UserDataPart{Content: []byte("#!/bin/sh")})
+	assert.NotNil(t, err)
+}
+
+func TestIgnitionConfig_Marshal(t *testing.T) {
+	mode := 420
+	config := IgnitionConfig{
+		Passwd: IgnitionPasswd{Users: []IgnitionUser{{Name: "core", SSHAuthorizedKeys: []string{"ssh-ed25519 AAAA core"}}}},
+		Storage: IgnitionStorage{Files: []IgnitionFile{{
+			Path:     "/etc/hostname",
+			Mode:     &mode,
+			Contents: NewIgnitionFileContents([]byte("web")),
+		}}},
+	}
+	data, err := config.Marshal()
+	assert.Nil(t, err, "Marshal returned an error %v", err)
+	assert.Equal(t, `{"ignition":{"version":"3.3.0"},"passwd":{"users":[{"name":"core","sshAuthorizedKeys":["ssh-ed25519 AAAA core"]}]},"storage":{"files":[{"path":"/etc/hostname","mode":420,"contents":{"source":"data:;base64,d2Vi"}}]}}`, string(data))
+	assert.Nil(t, ValidateUserData(data))
+
+	_, err = IgnitionConfig{Passwd: IgnitionPasswd{Users: []IgnitionUser{{}}}}.Marshal()
+	assert.NotNil(t, err)
+}
+
+func TestValidateUserData(t *testing.T) {
+	type testCase struct {
+		data     []byte
+		isFailed bool
+	}
+	testCases := []testCase{
+		{data: []byte("#cloud-config\nruncmd: [ls]\n")},
+		{data: []byte("#!/bin/bash\necho hello\n")},
+		{data: BuildCloudbaseInitScript("Write-Host hello")},
+		{data: []byte(`{"ignition": {"version": "3.3.0"}}`)},
+		{data: nil, isFailed: true},
+		{data: []byte("#cloud-config\nruncmd: [ls\n"), isFailed: true},
+		{data: []byte(`{"ignition": {}}`), isFailed: true},
+		{data: []byte(`{"ignition": `), isFailed: true},
+		{data: []byte("hello"), isFailed: true},
+	}
+	for _, test := range testCases {
+		err := ValidateUserData(test.data)
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "ValidateUserData returned an error %v", err)
+		}
+	}
+}
+
+func TestEncodeUserData(t *testing.T) {
+	encoded, err := EncodeUserData([]byte("#!/bin/sh\necho hello\n"))
+	assert.Nil(t, err, "EncodeUserData returned an error %v", err)
+	assert.Equal(t, "IyEvYmluL3NoCmVjaG8gaGVsbG8K", *encoded)
+
+	_, err = EncodeUserData([]byte("hello"))
+	assert.NotNil(t, err)
+}
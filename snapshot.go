@@ -18,6 +18,11 @@ type StorageSnapshotOperator interface {
 	GetDeletedSnapshots(ctx context.Context) ([]StorageSnapshot, error)
 	RollbackStorage(ctx context.Context, storageID, snapshotID string, body StorageRollbackRequest) error
 	ExportStorageSnapshotToS3(ctx context.Context, storageID, snapshotID string, body StorageSnapshotExportToS3Request) error
+	ApplySnapshotRetentionPolicy(ctx context.Context, storageID string, policy SnapshotRetentionPolicy, dryRun bool) (SnapshotRetentionReport, error)
+	CreateSnapshotGroup(ctx context.Context, serverID string, body SnapshotGroupCreateRequest) (SnapshotGroup, error)
+	GetSnapshotGroup(ctx context.Context, serverID, groupID string) (SnapshotGroup, error)
+	RollbackSnapshotGroup(ctx context.Context, serverID, groupID string) error
+	ExportStorageSnapshotToObjectStorage(ctx context.Context, storageID, snapshotID string, body SnapshotExportRequest) (SnapshotExportResponse, error)
 }
 
 // StorageSnapshotList holds a list of storage snapshots.
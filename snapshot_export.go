@@ -0,0 +1,166 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+)
+
+// DefaultObjectStorageHost is the host of the gridscale object storage.
+const DefaultObjectStorageHost = "gos3.io"
+
+// Stages of a snapshot export, reported to SnapshotExportRequest.Progress.
+const (
+	SnapshotExportStagePreparing  = "preparing"
+	SnapshotExportStageExporting  = "exporting"
+	SnapshotExportStageCleaningUp = "cleaning up"
+	SnapshotExportStageDone       = "done"
+)
+
+// SnapshotExportRequest represents a request for exporting a snapshot to the gridscale object storage.
+type SnapshotExportRequest struct {
+	// Bucket that the snapshot will be uploaded to.
+	Bucket string
+
+	// Prefix of the file name. The file name is the prefix followed by the snapshot name
+	// and a UTC timestamp, e.g. "backups/db-20240331T180000Z.gz". Optional.
+	FilenamePrefix string
+
+	// Is the file private?
+	Private bool
+
+	// Host of the object storage. Leave it empty to use the object storage of the snapshot's location,
+	// i.e. "<ObjectStorageRegion>.gos3.io", or DefaultObjectStorageHost if the location has no region.
+	Host string
+
+	// Access key and secret key of the object storage. Leave them empty to create a temporary access key,
+	// which is deleted after the export.
+	AccessKey string
+	SecretKey string
+
+	// Called when the export enters a new stage (see SnapshotExportStage* constants). Optional.
+	Progress func(stage string)
+}
+
+// SnapshotExportResponse represents the result of a snapshot export.
+type SnapshotExportResponse struct {
+	// Host of the object storage.
+	Host string
+
+	// Bucket the snapshot was uploaded to.
+	Bucket string
+
+	// Name of the uploaded file.
+	Filename string
+}
+
+// ExportStorageSnapshotToObjectStorage exports a storage's snapshot to the gridscale object storage
+// and waits until the export completes, regardless of whether the client is synchronous.
+func (c *Client) ExportStorageSnapshotToObjectStorage(ctx context.Context, storageID, snapshotID string, body SnapshotExportRequest) (response SnapshotExportResponse, err error) {
+	if body.Bucket == "" {
+		return SnapshotExportResponse{}, errors.New("'Bucket' is empty")
+	}
+	if (body.AccessKey == "") != (body.SecretKey == "") {
+		return SnapshotExportResponse{}, errors.New("'AccessKey' and 'SecretKey' must be set together")
+	}
+	progress := func(stage string) {
+		if body.Progress != nil {
+			body.Progress(stage)
+		}
+	}
+	progress(SnapshotExportStagePreparing)
+	snapshot, err := c.GetStorageSnapshot(ctx, storageID, snapshotID)
+	if err != nil {
+		return SnapshotExportResponse{}, err
+	}
+	host := body.Host
+	if host == "" {
+		host, err = c.getObjectStorageHost(ctx, snapshot.Properties.LocationUUID)
+		if err != nil {
+			return SnapshotExportResponse{}, err
+		}
+	}
+	response = SnapshotExportResponse{
+		Host:     host,
+		Bucket:   body.Bucket,
+		Filename: fmt.Sprintf("%s%s-%s.gz", body.FilenamePrefix, snapshot.Properties.Name, time.Now().UTC().Format("20060102T150405Z")),
+	}
+
+	defer func() {
+		if err == nil {
+			progress(SnapshotExportStageDone)
+		}
+	}()
+
+	accessKey, secretKey := body.AccessKey, body.SecretKey
+	if accessKey == "" {
+		var key ObjectStorageAccessKeyCreateResponse
+		key, err = c.AdvancedCreateObjectStorageAccessKey(ctx, ObjectStorageAccessKeyCreateRequest{
+			Comment: fmt.Sprintf("temporary key for exporting snapshot %s", snapshotID),
+		})
+		if err != nil {
+			return SnapshotExportResponse{}, err
+		}
+		accessKey, secretKey = key.AccessKey.AccessKey, key.AccessKey.SecretKey
+		defer func() {
+			progress(SnapshotExportStageCleaningUp)
+			deleteErr := c.DeleteObjectStorageAccessKey(ctx, accessKey)
+			if deleteErr != nil && err == nil {
+				response, err = SnapshotExportResponse{}, fmt.Errorf("deleting temporary access key failed: %v", deleteErr)
+			}
+		}()
+	}
+
+	progress(SnapshotExportStageExporting)
+	err = c.exportStorageSnapshotToS3AndWait(ctx, storageID, snapshotID, StorageSnapshotExportToS3Request{
+		S3auth: S3auth{
+			Host:      host,
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+		},
+		S3data: S3data{
+			Host:     "https://" + host,
+			Bucket:   body.Bucket,
+			Filename: response.Filename,
+			Private:  body.Private,
+		},
+	})
+	if err != nil {
+		return SnapshotExportResponse{}, err
+	}
+	return response, nil
+}
+
+// getObjectStorageHost returns the object storage host of a location.
+func (c *Client) getObjectStorageHost(ctx context.Context, locationID string) (string, error) {
+	location, err := c.GetLocation(ctx, locationID)
+	if err != nil {
+		return "", err
+	}
+	region := location.Properties.Features.ObjectStorageRegion
+	if region == "" {
+		return DefaultObjectStorageHost, nil
+	}
+	return fmt.Sprintf("%s.%s", region, DefaultObjectStorageHost), nil
+}
+
+// exportStorageSnapshotToS3AndWait exports a storage's snapshot to S3 and waits for the request to complete.
+func (c *Client) exportStorageSnapshotToS3AndWait(ctx context.Context, storageID, snapshotID string, body StorageSnapshotExportToS3Request) error {
+	if !isValidUUID(storageID) || !isValidUUID(snapshotID) {
+		return errors.New("'storageID' or 'snapshotID' is invalid")
+	}
+	r := gsRequest{
+		uri:                 path.Join(apiStorageBase, storageID, "snapshots", snapshotID, "export_to_s3"),
+		method:              http.MethodPatch,
+		body:                body,
+		skipCheckingRequest: true,
+	}
+	requestUUID, _, err := r.retryHTTPRequest(ctx, c.cfg)
+	if err != nil {
+		return err
+	}
+	return c.waitForRequestCompleted(ctx, requestUUID)
+}
@@ -0,0 +1,96 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ExportStorageSnapshotToObjectStorage(t *testing.T) {
+	server, client, mux := setupTestClient(false)
+	defer server.Close()
+	exportRequestUUID := "7a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	var exported StorageSnapshotExportToS3Request
+	var deletedKeys []string
+	var isExportWaited bool
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "snapshots", dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareStorageSnapshotHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiLocationBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		location := getMockLocation()
+		location.Properties.Features.ObjectStorageRegion = "de-fra"
+		res, _ := json.Marshal(location)
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiObjectStorageBase, "access_keys"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(getMockObjectStorageAccessKeyCreateResponse())
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiObjectStorageBase, "access_keys", "dummy-access-key"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		deletedKeys = append(deletedKeys, "dummy-access-key")
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "snapshots", dummyUUID, "export_to_s3"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		w.Header().Set(requestUUIDHeader, exportRequestUUID)
+		json.NewDecoder(r.Body).Decode(&exported)
+	})
+	mux.HandleFunc(path.Join(requestBase, exportRequestUUID), func(w http.ResponseWriter, r *http.Request) {
+		isExportWaited = true
+		fmt.Fprintf(w, `{"%s": {"status":"done"}}`, exportRequestUUID)
+	})
+
+	var stages []string
+	response, err := client.ExportStorageSnapshotToObjectStorage(emptyCtx, dummyUUID, dummyUUID, SnapshotExportRequest{
+		Bucket:         "backups",
+		FilenamePrefix: "db/",
+		Private:        true,
+		Progress: func(stage string) {
+			stages = append(stages, stage)
+		},
+	})
+	assert.Nil(t, err, "ExportStorageSnapshotToObjectStorage returned an error %v", err)
+	assert.Equal(t, "de-fra.gos3.io", response.Host)
+	assert.Regexp(t, regexp.MustCompile(`^db/test-\d{8}T\d{6}Z\.gz$`), response.Filename)
+	assert.Equal(t, StorageSnapshotExportToS3Request{
+		S3auth: S3auth{Host: "de-fra.gos3.io", AccessKey: "dummy-access-key", SecretKey: "dummy-secret-key"},
+		S3data: S3data{Host: "https://de-fra.gos3.io", Bucket: "backups", Filename: response.Filename, Private: true},
+	}, exported)
+	assert.True(t, isExportWaited)
+	assert.Equal(t, []string{"dummy-access-key"}, deletedKeys)
+	assert.Equal(t, []string{
+		SnapshotExportStagePreparing,
+		SnapshotExportStageExporting,
+		SnapshotExportStageCleaningUp,
+		SnapshotExportStageDone,
+	}, stages)
+
+	deletedKeys = nil
+	response, err = client.ExportStorageSnapshotToObjectStorage(emptyCtx, dummyUUID, dummyUUID, SnapshotExportRequest{
+		Bucket:    "backups",
+		Host:      "gos3.io",
+		AccessKey: "own-access-key",
+		SecretKey: "own-secret-key",
+	})
+	assert.Nil(t, err, "ExportStorageSnapshotToObjectStorage returned an error %v", err)
+	assert.Equal(t, "gos3.io", response.Host)
+	assert.Equal(t, "own-access-key", exported.S3auth.AccessKey)
+	assert.Nil(t, deletedKeys)
+
+	_, err = client.ExportStorageSnapshotToObjectStorage(emptyCtx, dummyUUID, dummyUUID, SnapshotExportRequest{})
+	assert.NotNil(t, err)
+	_, err = client.ExportStorageSnapshotToObjectStorage(emptyCtx, dummyUUID, dummyUUID, SnapshotExportRequest{Bucket: "backups", AccessKey: "key"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,195 @@
+package gsclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotGroupLabelPrefix is the prefix of the label shared by all snapshots of a snapshot group.
+// The label is the prefix followed by the group ID.
+const SnapshotGroupLabelPrefix = "snapshot-group:"
+
+// SnapshotGroupHook is called before and after taking the snapshots of a snapshot group,
+// e.g. to quiesce the guest's file systems and databases.
+type SnapshotGroupHook func(ctx context.Context, server Server) error
+
+// SnapshotGroupCreateRequest represents a request for creating a snapshot group.
+type SnapshotGroupCreateRequest struct {
+	// Name of the snapshots. Optional.
+	Name string
+
+	// Additional labels of the snapshots. Optional.
+	Labels []string
+
+	// Called before taking the snapshots. If it fails, no snapshot is taken. Optional.
+	PreHook SnapshotGroupHook
+
+	// Called after taking the snapshots, also if taking a snapshot or PreHook failed,
+	// so that a partly quiesced guest is always resumed. Optional.
+	PostHook SnapshotGroupHook
+}
+
+// SnapshotGroup represents the snapshots of all storages of a server taken at the same time.
+type SnapshotGroup struct {
+	// The ID of the group.
+	GroupID string
+
+	// The UUID of the server.
+	ServerUUID string
+
+	// The snapshots of the group.
+	Members []SnapshotGroupMember
+}
+
+// SnapshotGroupMember represents a snapshot of a snapshot group.
+type SnapshotGroupMember struct {
+	// The UUID of the storage.
+	StorageUUID string
+
+	// The UUID of the snapshot.
+	SnapshotUUID string
+}
+
+// CreateSnapshotGroup snapshots all storages of a server concurrently and tags the snapshots with
+// a shared group label. If a snapshot fails, the other snapshots of the group are deleted.
+// If only PostHook fails, the complete group is returned together with the error of PostHook.
+func (c *Client) CreateSnapshotGroup(ctx context.Context, serverID string, body SnapshotGroupCreateRequest) (SnapshotGroup, error) {
+	server, err := c.GetServer(ctx, serverID)
+	if err != nil {
+		return SnapshotGroup{}, err
+	}
+	storages := server.Properties.Relations.Storages
+	if len(storages) == 0 {
+		return SnapshotGroup{}, fmt.Errorf("server %s has no storages", serverID)
+	}
+	group := SnapshotGroup{
+		GroupID:    uuid.New().String(),
+		ServerUUID: serverID,
+		Members:    make([]SnapshotGroupMember, len(storages)),
+	}
+	runPostHook := func() error {
+		if body.PostHook == nil {
+			return nil
+		}
+		err := body.PostHook(ctx, server)
+		if err != nil {
+			return fmt.Errorf("post hook failed: %v", err)
+		}
+		return nil
+	}
+	if body.PreHook != nil {
+		err = body.PreHook(ctx, server)
+		if err != nil {
+			return SnapshotGroup{}, joinErrors([]error{fmt.Errorf("pre hook failed: %v", err), runPostHook()})
+		}
+	}
+	labels := append(append(make([]string, 0), body.Labels...), SnapshotGroupLabelPrefix+group.GroupID)
+	errs := runConcurrently(len(storages), func(i int) error {
+		storageID := storages[i].ObjectUUID
+		response, err := c.CreateStorageSnapshot(ctx, storageID, StorageSnapshotCreateRequest{
+			Name:   body.Name,
+			Labels: labels,
+		})
+		group.Members[i] = SnapshotGroupMember{StorageUUID: storageID, SnapshotUUID: response.ObjectUUID}
+		if err != nil {
+			return fmt.Errorf("snapshot of storage %s failed: %v", storageID, err)
+		}
+		return nil
+	})
+	postHookErr := runPostHook()
+	err = joinErrors(errs)
+	if err != nil {
+		err = joinErrors([]error{err, postHookErr})
+		cleanupErr := c.deleteSnapshotGroupMembers(ctx, group.Members)
+		if cleanupErr != nil {
+			return SnapshotGroup{}, fmt.Errorf("%v; cleanup failed: %v", err, cleanupErr)
+		}
+		return SnapshotGroup{}, err
+	}
+	// The snapshots are complete and consistent, so they are kept if only the post hook failed.
+	if postHookErr != nil {
+		return group, postHookErr
+	}
+	return group, nil
+}
+
+// GetSnapshotGroup gets a snapshot group of a server based on the group ID.
+func (c *Client) GetSnapshotGroup(ctx context.Context, serverID, groupID string) (SnapshotGroup, error) {
+	server, err := c.GetServer(ctx, serverID)
+	if err != nil {
+		return SnapshotGroup{}, err
+	}
+	group := SnapshotGroup{GroupID: groupID, ServerUUID: serverID}
+	for _, storage := range server.Properties.Relations.Storages {
+		snapshots, err := c.GetStorageSnapshotList(ctx, storage.ObjectUUID)
+		if err != nil {
+			return SnapshotGroup{}, err
+		}
+		for _, snapshot := range snapshots {
+			if hasLabel(snapshot.Properties.Labels, SnapshotGroupLabelPrefix+groupID) {
+				group.Members = append(group.Members, SnapshotGroupMember{
+					StorageUUID:  storage.ObjectUUID,
+					SnapshotUUID: snapshot.Properties.ObjectUUID,
+				})
+			}
+		}
+	}
+	if len(group.Members) == 0 {
+		return SnapshotGroup{}, fmt.Errorf("snapshot group %s of server %s not found", groupID, serverID)
+	}
+	return group, nil
+}
+
+// RollbackSnapshotGroup restores all storages of a snapshot group concurrently.
+// The server must be stopped, and every storage of the server must have a snapshot in the group.
+func (c *Client) RollbackSnapshotGroup(ctx context.Context, serverID, groupID string) error {
+	server, err := c.GetServer(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	if server.Properties.Power {
+		return fmt.Errorf("server %s must be stopped before rolling back snapshot group %s", serverID, groupID)
+	}
+	group, err := c.GetSnapshotGroup(ctx, serverID, groupID)
+	if err != nil {
+		return err
+	}
+	for _, storage := range server.Properties.Relations.Storages {
+		found := false
+		for _, member := range group.Members {
+			if member.StorageUUID == storage.ObjectUUID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("snapshot group %s has no snapshot of storage %s", groupID, storage.ObjectUUID)
+		}
+	}
+	errs := runConcurrently(len(group.Members), func(i int) error {
+		member := group.Members[i]
+		err := c.RollbackStorage(ctx, member.StorageUUID, member.SnapshotUUID, StorageRollbackRequest{Rollback: true})
+		if err != nil {
+			return fmt.Errorf("rollback of storage %s failed: %v", member.StorageUUID, err)
+		}
+		return nil
+	})
+	return joinErrors(errs)
+}
+
+// deleteSnapshotGroupMembers deletes the snapshots of a partial snapshot group.
+func (c *Client) deleteSnapshotGroupMembers(ctx context.Context, members []SnapshotGroupMember) error {
+	var errs []error
+	for _, member := range members {
+		if member.SnapshotUUID == "" {
+			continue
+		}
+		err := c.DeleteStorageSnapshot(ctx, member.StorageUUID, member.SnapshotUUID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deleting snapshot %s failed: %v", member.SnapshotUUID, err))
+		}
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,182 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockSecondStorageUUID  = "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	mockFirstSnapshotUUID  = "11111111-1111-4111-8111-111111111111"
+	mockSecondSnapshotUUID = "22222222-2222-4222-8222-222222222222"
+)
+
+func TestClient_CreateSnapshotGroup(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var mu sync.Mutex
+	var isSnapshotFailed bool
+	var createdLabels [][]string
+	var deleted []string
+	prepareSnapshotGroupServerHTTPGet(mux, false)
+	snapshotUUIDs := map[string]string{dummyUUID: mockFirstSnapshotUUID, mockSecondStorageUUID: mockSecondSnapshotUUID}
+	for storageID, snapshotID := range snapshotUUIDs {
+		storageID, snapshotID := storageID, snapshotID
+		mux.HandleFunc(path.Join(apiStorageBase, storageID, "snapshots"), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			if isSnapshotFailed && storageID == mockSecondStorageUUID {
+				w.WriteHeader(400)
+				return
+			}
+			var body StorageSnapshotCreateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			createdLabels = append(createdLabels, body.Labels)
+			mu.Unlock()
+			fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, snapshotID, dummyRequestUUID)
+		})
+		mux.HandleFunc(path.Join(apiStorageBase, storageID, "snapshots", snapshotID), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			deleted = append(deleted, snapshotID)
+		})
+	}
+	var hooks []string
+	body := SnapshotGroupCreateRequest{
+		Labels: []string{"db"},
+		PreHook: func(ctx context.Context, server Server) error {
+			hooks = append(hooks, "pre "+server.Properties.ObjectUUID)
+			return nil
+		},
+		PostHook: func(ctx context.Context, server Server) error {
+			hooks = append(hooks, "post "+server.Properties.ObjectUUID)
+			return nil
+		},
+	}
+	group, err := client.CreateSnapshotGroup(emptyCtx, dummyUUID, body)
+	assert.Nil(t, err, "CreateSnapshotGroup returned an error %v", err)
+	assert.Equal(t, []string{"pre " + dummyUUID, "post " + dummyUUID}, hooks)
+	assert.Equal(t, []SnapshotGroupMember{
+		{StorageUUID: dummyUUID, SnapshotUUID: mockFirstSnapshotUUID},
+		{StorageUUID: mockSecondStorageUUID, SnapshotUUID: mockSecondSnapshotUUID},
+	}, group.Members)
+	assert.Equal(t, [][]string{
+		{"db", SnapshotGroupLabelPrefix + group.GroupID},
+		{"db", SnapshotGroupLabelPrefix + group.GroupID},
+	}, createdLabels)
+	assert.Nil(t, deleted)
+
+	hooks = nil
+	isSnapshotFailed = true
+	_, err = client.CreateSnapshotGroup(emptyCtx, dummyUUID, body)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"pre " + dummyUUID, "post " + dummyUUID}, hooks)
+	assert.Equal(t, []string{mockFirstSnapshotUUID}, deleted)
+
+	hooks = nil
+	deleted = nil
+	isSnapshotFailed = false
+	postHook := body.PostHook
+	body.PostHook = func(ctx context.Context, server Server) error {
+		return errors.New("just test")
+	}
+	group, err = client.CreateSnapshotGroup(emptyCtx, dummyUUID, body)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(group.Members))
+	assert.Nil(t, deleted)
+
+	hooks = nil
+	body.PostHook = postHook
+	body.PreHook = func(ctx context.Context, server Server) error {
+		return errors.New("just test")
+	}
+	_, err = client.CreateSnapshotGroup(emptyCtx, dummyUUID, body)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"post " + dummyUUID}, hooks)
+	assert.Nil(t, deleted)
+}
+
+func TestClient_RollbackSnapshotGroup(t *testing.T) {
+	testCases := []struct {
+		power          bool
+		groupStorages  []string
+		isFailed       bool
+		expectedRolled []string
+	}{
+		{
+			power:          false,
+			groupStorages:  []string{dummyUUID, mockSecondStorageUUID},
+			expectedRolled: []string{mockSecondStorageUUID, dummyUUID},
+		},
+		{
+			power:         true,
+			groupStorages: []string{dummyUUID, mockSecondStorageUUID},
+			isFailed:      true,
+		},
+		{
+			power:         false,
+			groupStorages: []string{dummyUUID},
+			isFailed:      true,
+		},
+	}
+	for _, test := range testCases {
+		server, client, mux := setupTestClient(true)
+		var mu sync.Mutex
+		var rolled []string
+		prepareSnapshotGroupServerHTTPGet(mux, test.power)
+		for _, storageID := range []string{dummyUUID, mockSecondStorageUUID} {
+			storageID := storageID
+			mux.HandleFunc(path.Join(apiStorageBase, storageID, "snapshots"), func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+				snapshot := getMockStorageSnapshot("active")
+				for _, groupStorage := range test.groupStorages {
+					if groupStorage == storageID {
+						snapshot.Properties.Labels = []string{SnapshotGroupLabelPrefix + "group"}
+					}
+				}
+				res, _ := json.Marshal(snapshot.Properties)
+				fmt.Fprintf(w, `{"snapshots": {"%s": %s}}`, dummyUUID, string(res))
+			})
+			mux.HandleFunc(path.Join(apiStorageBase, storageID, "snapshots", dummyUUID, "rollback"), func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+				mu.Lock()
+				rolled = append(rolled, storageID)
+				mu.Unlock()
+			})
+		}
+		err := client.RollbackSnapshotGroup(emptyCtx, dummyUUID, "group")
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "RollbackSnapshotGroup returned an error %v", err)
+		}
+		sort.Strings(rolled)
+		assert.Equal(t, test.expectedRolled, rolled)
+		server.Close()
+	}
+}
+
+func prepareSnapshotGroupServerHTTPGet(mux *http.ServeMux, power bool) {
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		server := getMockServer(power, "active")
+		server.Properties.Relations.Storages = []ServerStorageRelationProperties{
+			{ObjectUUID: dummyUUID},
+			{ObjectUUID: mockSecondStorageUUID},
+		}
+		res, _ := json.Marshal(server)
+		fmt.Fprint(w, string(res))
+	})
+}
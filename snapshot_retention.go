@@ -0,0 +1,140 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SnapshotRetentionPolicy represents a grandfather-father-son retention policy for storage snapshots.
+// For each period, the newest snapshot of the given number of most recent periods is kept.
+// A snapshot may be kept for several periods at once.
+type SnapshotRetentionPolicy struct {
+	// Number of hourly snapshots to keep.
+	Hourly int
+
+	// Number of daily snapshots to keep.
+	Daily int
+
+	// Number of weekly (ISO week) snapshots to keep.
+	Weekly int
+
+	// Number of monthly snapshots to keep.
+	Monthly int
+
+	// Time zone defining the period boundaries. Leave it nil to use UTC.
+	Location *time.Location
+}
+
+// SnapshotRetentionDecision represents a snapshot kept by a retention policy.
+type SnapshotRetentionDecision struct {
+	// The kept snapshot.
+	Snapshot StorageSnapshot
+
+	// Reasons for keeping the snapshot, e.g. "hourly", "daily", "weekly", "monthly" or "not active".
+	Reasons []string
+}
+
+// SnapshotRetentionReport represents the result of applying a retention policy to the snapshots of a storage.
+type SnapshotRetentionReport struct {
+	// Snapshots which are kept, newest first.
+	Kept []SnapshotRetentionDecision
+
+	// Snapshots which are (or would be in a dry run) deleted, newest first.
+	Deleted []StorageSnapshot
+
+	// True if the snapshots were not actually deleted.
+	DryRun bool
+}
+
+// PlanSnapshotRetention decides which snapshots are kept and which are deleted by a retention policy,
+// based on the create time of the snapshots. Snapshots which are not active are always kept.
+func PlanSnapshotRetention(snapshots []StorageSnapshot, policy SnapshotRetentionPolicy) (SnapshotRetentionReport, error) {
+	var report SnapshotRetentionReport
+	if policy.Hourly < 0 || policy.Daily < 0 || policy.Weekly < 0 || policy.Monthly < 0 {
+		return report, errors.New("retention policy counts must not be negative")
+	}
+	if policy.Hourly+policy.Daily+policy.Weekly+policy.Monthly == 0 {
+		return report, errors.New("retention policy would delete all snapshots")
+	}
+	loc := policy.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	sorted := make([]StorageSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Properties.CreateTime.After(sorted[j].Properties.CreateTime.Time)
+	})
+
+	periods := []struct {
+		name   string
+		keep   int
+		bucket func(t time.Time) string
+	}{
+		{"hourly", policy.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{"daily", policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{"weekly", policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"monthly", policy.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	reasons := make([][]string, len(sorted))
+	for _, period := range periods {
+		kept := 0
+		lastBucket := ""
+		for i, snapshot := range sorted {
+			if kept >= period.keep {
+				break
+			}
+			if snapshot.Properties.Status != resourceActiveStatus {
+				continue
+			}
+			bucket := period.bucket(snapshot.Properties.CreateTime.In(loc))
+			if bucket == lastBucket {
+				continue
+			}
+			lastBucket = bucket
+			kept++
+			reasons[i] = append(reasons[i], period.name)
+		}
+	}
+	for i, snapshot := range sorted {
+		if snapshot.Properties.Status != resourceActiveStatus {
+			reasons[i] = append(reasons[i], "not active")
+		}
+		if len(reasons[i]) == 0 {
+			report.Deleted = append(report.Deleted, snapshot)
+			continue
+		}
+		report.Kept = append(report.Kept, SnapshotRetentionDecision{Snapshot: snapshot, Reasons: reasons[i]})
+	}
+	return report, nil
+}
+
+// ApplySnapshotRetentionPolicy applies a retention policy to the snapshots of a storage, see PlanSnapshotRetention.
+// In a dry run, the snapshots are not deleted, only the report is returned.
+func (c *Client) ApplySnapshotRetentionPolicy(ctx context.Context, storageID string, policy SnapshotRetentionPolicy, dryRun bool) (SnapshotRetentionReport, error) {
+	snapshots, err := c.GetStorageSnapshotList(ctx, storageID)
+	if err != nil {
+		return SnapshotRetentionReport{}, err
+	}
+	report, err := PlanSnapshotRetention(snapshots, policy)
+	if err != nil {
+		return report, err
+	}
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+	for _, snapshot := range report.Deleted {
+		err = c.DeleteStorageSnapshot(ctx, storageID, snapshot.Properties.ObjectUUID)
+		if err != nil {
+			return report, fmt.Errorf("deleting snapshot %s failed: %v", snapshot.Properties.ObjectUUID, err)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,110 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSnapshotRetention(t *testing.T) {
+	snapshots := getMockRetentionSnapshots()
+	policy := SnapshotRetentionPolicy{Hourly: 2, Daily: 2, Weekly: 2, Monthly: 3}
+	report, err := PlanSnapshotRetention(snapshots, policy)
+	assert.Nil(t, err, "PlanSnapshotRetention returned an error %v", err)
+
+	kept := make(map[string][]string)
+	for _, decision := range report.Kept {
+		kept[decision.Snapshot.Properties.Name] = decision.Reasons
+	}
+	assert.Equal(t, map[string][]string{
+		"2024-03-31T19": {"not active"},
+		"2024-03-31T18": {"hourly", "daily", "weekly", "monthly"},
+		"2024-03-31T12": {"hourly"},
+		"2024-03-30T18": {"daily"},
+		"2024-03-24T18": {"weekly"},
+		"2024-02-15T12": {"monthly"},
+		"2024-01-10T12": {"monthly"},
+	}, kept)
+	var deleted []string
+	for _, snapshot := range report.Deleted {
+		deleted = append(deleted, snapshot.Properties.Name)
+	}
+	assert.Equal(t, []string{"2024-03-31T06", "2024-03-29T18", "2024-03-20T12"}, deleted)
+
+	_, err = PlanSnapshotRetention(snapshots, SnapshotRetentionPolicy{})
+	assert.NotNil(t, err)
+	_, err = PlanSnapshotRetention(snapshots, SnapshotRetentionPolicy{Daily: -1, Monthly: 1})
+	assert.NotNil(t, err)
+}
+
+func TestClient_ApplySnapshotRetentionPolicy(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	snapshots := getMockRetentionSnapshots()
+	var deleted []string
+	uri := path.Join(apiStorageBase, dummyUUID, "snapshots")
+	mux.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		list := StorageSnapshotList{List: make(map[string]StorageSnapshotProperties)}
+		for _, snapshot := range snapshots {
+			list.List[snapshot.Properties.ObjectUUID] = snapshot.Properties
+		}
+		res, _ := json.Marshal(list)
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(uri+"/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		deleted = append(deleted, strings.TrimPrefix(r.URL.Path, uri+"/"))
+	})
+	policy := SnapshotRetentionPolicy{Hourly: 2, Daily: 2, Weekly: 2, Monthly: 3}
+	report, err := client.ApplySnapshotRetentionPolicy(emptyCtx, dummyUUID, policy, true)
+	assert.Nil(t, err, "ApplySnapshotRetentionPolicy returned an error %v", err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, len(report.Deleted))
+	assert.Nil(t, deleted)
+
+	report, err = client.ApplySnapshotRetentionPolicy(emptyCtx, dummyUUID, policy, false)
+	assert.Nil(t, err, "ApplySnapshotRetentionPolicy returned an error %v", err)
+	assert.False(t, report.DryRun)
+	var expected []string
+	for _, snapshot := range report.Deleted {
+		expected = append(expected, snapshot.Properties.ObjectUUID)
+	}
+	assert.Equal(t, expected, deleted)
+}
+
+func getMockRetentionSnapshots() []StorageSnapshot {
+	createTimes := []string{
+		"2024-01-10T12",
+		"2024-03-31T18",
+		"2024-03-31T12",
+		"2024-03-31T06",
+		"2024-03-30T18",
+		"2024-03-29T18",
+		"2024-03-24T18",
+		"2024-03-20T12",
+		"2024-02-15T12",
+		"2024-03-31T19",
+	}
+	var snapshots []StorageSnapshot
+	for i, createTime := range createTimes {
+		snapshot := getMockStorageSnapshot("active")
+		snapshot.Properties.ObjectUUID = fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+		snapshot.Properties.Name = createTime
+		created, _ := time.Parse("2006-01-02T15", createTime)
+		snapshot.Properties.CreateTime = GSTime{created}
+		if createTime == "2024-03-31T19" {
+			snapshot.Properties.Status = "in-provisioning"
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
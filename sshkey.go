@@ -3,8 +3,10 @@ package gsclient
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"path"
+	"strings"
 )
 
 // SSHKeyOperator provides an interface for operations on SSH keys.
@@ -15,6 +17,9 @@ type SSHKeyOperator interface {
 	DeleteSshkey(ctx context.Context, id string) error
 	UpdateSshkey(ctx context.Context, id string, body SshkeyUpdateRequest) error
 	GetSshkeyEventList(ctx context.Context, id string) ([]Event, error)
+	GetSshPublicKeysByName(ctx context.Context, names ...string) ([]string, error)
+	GetDuplicateSshkeys(ctx context.Context) ([][]Sshkey, error)
+	SyncSshkeys(ctx context.Context, keys map[string]SSHPublicKey, opts SshkeySyncOptions) (SshkeySyncReport, error)
 }
 
 // SshkeyList holds a list of SSH keys.
@@ -119,6 +124,28 @@ func (c *Client) GetSshkeyList(ctx context.Context) ([]Sshkey, error) {
 	return sshKeys, err
 }
 
+// GetSshPublicKeysByName returns the public keys of the SSH keys with the given names,
+// e.g. for CloudConfig.SSHAuthorizedKeys.
+func (c *Client) GetSshPublicKeysByName(ctx context.Context, names ...string) ([]string, error) {
+	sshkeys, err := c.GetSshkeyList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	publicKeys := make(map[string]string)
+	for _, sshkey := range sshkeys {
+		publicKeys[sshkey.Properties.Name] = strings.TrimSpace(sshkey.Properties.Sshkey)
+	}
+	var keys []string
+	for _, name := range names {
+		key, ok := publicKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("SSH key %s not found", name)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 // CreateSshkey creates a new SSH key.
 //
 // See: https://gridscale.io/en//api-documentation/index.html#operation/createSshKey
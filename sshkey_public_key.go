@@ -0,0 +1,214 @@
+package gsclient
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// minRSAKeyBits is the minimum size of an RSA key, smaller keys are rejected by OpenSSH.
+const minRSAKeyBits = 1024
+
+// ecdsaCurveBits maps the curve names of ECDSA keys to their sizes.
+var ecdsaCurveBits = map[string]int{
+	"nistp256": 256,
+	"nistp384": 384,
+	"nistp521": 521,
+}
+
+// SSHPublicKey represents a parsed OpenSSH public key.
+type SSHPublicKey struct {
+	// The key type, e.g. "ssh-ed25519" or "ssh-rsa".
+	Type string
+
+	// The size of the key in bits.
+	Bits int
+
+	// The comment of the key, e.g. "user@host". Empty if the key has no comment.
+	Comment string
+
+	// The key in SSH wire format.
+	data []byte
+}
+
+// ParseSSHPublicKey parses and validates an OpenSSH public key in authorized_keys format,
+// e.g. "ssh-ed25519 AAAA... user@host". Options in front of the key type are ignored.
+// Supported key types are RSA, DSA, ECDSA, Ed25519 and their security key variants; SSH-1 (rsa1) keys are not supported.
+// CreateSshkey and UpdateSshkey do not call it, as the API accepts further formats, e.g. certificates.
+func ParseSSHPublicKey(key string) (SSHPublicKey, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return SSHPublicKey{}, errors.New("SSH public key is empty")
+	}
+	if strings.ContainsAny(key, "\r\n") {
+		return SSHPublicKey{}, errors.New("SSH public key has more than one line")
+	}
+	fields := strings.Fields(key)
+	// Skip options, e.g. `from="10.0.0.0/8" ssh-ed25519 AAAA...`.
+	typeIndex := -1
+	for i, field := range fields {
+		if isSSHPublicKeyType(field) {
+			typeIndex = i
+			break
+		}
+	}
+	if typeIndex == -1 {
+		return SSHPublicKey{}, fmt.Errorf("SSH public key type %s is unknown", fields[0])
+	}
+	if typeIndex+1 >= len(fields) {
+		return SSHPublicKey{}, errors.New("SSH public key has no key data")
+	}
+	parsed := SSHPublicKey{
+		Type:    fields[typeIndex],
+		Comment: strings.Join(fields[typeIndex+2:], " "),
+	}
+	data, err := base64.StdEncoding.DecodeString(fields[typeIndex+1])
+	if err != nil {
+		return SSHPublicKey{}, fmt.Errorf("SSH public key data is not base64 encoded: %v", err)
+	}
+	parsed.data = data
+	parsed.Bits, err = parseSSHPublicKeyData(parsed.Type, data)
+	if err != nil {
+		return SSHPublicKey{}, err
+	}
+	return parsed, nil
+}
+
+// ParseAuthorizedKeys parses the keys of an authorized_keys file. Empty lines and comments are skipped.
+func ParseAuthorizedKeys(data []byte) ([]SSHPublicKey, error) {
+	var keys []SSHPublicKey
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := ParseSSHPublicKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+// String returns the key in authorized_keys format, without options.
+func (k SSHPublicKey) String() string {
+	key := k.Type + " " + base64.StdEncoding.EncodeToString(k.data)
+	if k.Comment != "" {
+		key += " " + k.Comment
+	}
+	return key
+}
+
+// FingerprintSHA256 returns the SHA256 fingerprint of the key as printed by ssh-keygen, e.g. "SHA256:afQKNg...".
+func (k SSHPublicKey) FingerprintSHA256() string {
+	sum := sha256.Sum256(k.data)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// FingerprintMD5 returns the MD5 fingerprint of the key as printed by ssh-keygen, e.g. "MD5:d9:60:01:...".
+func (k SSHPublicKey) FingerprintMD5() string {
+	sum := md5.Sum(k.data)
+	hexBytes := make([]string, len(sum))
+	for i, b := range sum {
+		hexBytes[i] = fmt.Sprintf("%02x", b)
+	}
+	return "MD5:" + strings.Join(hexBytes, ":")
+}
+
+// isSSHPublicKeyType checks if a string is a supported key type.
+func isSSHPublicKeyType(keyType string) bool {
+	switch keyType {
+	case "ssh-rsa", "ssh-dss", "ssh-ed25519", "sk-ssh-ed25519@openssh.com", "sk-ecdsa-sha2-nistp256@openssh.com":
+		return true
+	}
+	if !strings.HasPrefix(keyType, "ecdsa-sha2-") {
+		return false
+	}
+	_, ok := ecdsaCurveBits[strings.TrimPrefix(keyType, "ecdsa-sha2-")]
+	return ok
+}
+
+// parseSSHPublicKeyData validates the wire format of a key and returns its size in bits.
+func parseSSHPublicKeyData(keyType string, data []byte) (int, error) {
+	fields, err := readSSHWireStrings(data)
+	if err != nil {
+		return 0, err
+	}
+	if string(fields[0]) != keyType {
+		return 0, fmt.Errorf("SSH public key data has type %s instead of %s", fields[0], keyType)
+	}
+	invalidErr := fmt.Errorf("SSH public key data is invalid for type %s", keyType)
+	switch keyType {
+	case "ssh-rsa":
+		// exponent, modulus
+		if len(fields) != 3 {
+			return 0, invalidErr
+		}
+		bits := new(big.Int).SetBytes(fields[2]).BitLen()
+		if bits < minRSAKeyBits {
+			return 0, fmt.Errorf("RSA key has %d bits, at least %d bits are required", bits, minRSAKeyBits)
+		}
+		return bits, nil
+	case "ssh-dss":
+		// p, q, g, y
+		if len(fields) != 5 {
+			return 0, invalidErr
+		}
+		return new(big.Int).SetBytes(fields[1]).BitLen(), nil
+	case "ssh-ed25519":
+		if len(fields) != 2 || len(fields[1]) != 32 {
+			return 0, invalidErr
+		}
+		return 256, nil
+	case "sk-ssh-ed25519@openssh.com":
+		// key, application
+		if len(fields) != 3 || len(fields[1]) != 32 {
+			return 0, invalidErr
+		}
+		return 256, nil
+	case "sk-ecdsa-sha2-nistp256@openssh.com":
+		// curve, point, application
+		if len(fields) != 4 || string(fields[1]) != "nistp256" {
+			return 0, invalidErr
+		}
+		return 256, nil
+	}
+	// curve, point
+	curve := strings.TrimPrefix(keyType, "ecdsa-sha2-")
+	if len(fields) != 3 || string(fields[1]) != curve {
+		return 0, invalidErr
+	}
+	return ecdsaCurveBits[curve], nil
+}
+
+// readSSHWireStrings splits data in SSH wire format into its length-prefixed strings.
+func readSSHWireStrings(data []byte) ([][]byte, error) {
+	var fields [][]byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errors.New("SSH public key data is truncated")
+		}
+		length := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(length) > uint64(len(data)) {
+			return nil, errors.New("SSH public key data is truncated")
+		}
+		fields = append(fields, data[:length])
+		data = data[length:]
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("SSH public key data is empty")
+	}
+	return fields, nil
+}
@@ -0,0 +1,107 @@
+package gsclient
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dummySSHPublicKey   = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILc2TvMRPqlC33XareGmg0/2rBknj+Pxyf8Tv4OCBLd9 alice@example"
+	dummyRSAPublicKey   = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC0C8abK26nxpW7VEB1VN6LnBMDYAJDK1n/p+2NfLaoWi6QIj7K6H+LrH9V5a06HvuIQoal1ZgRalObTnT9PptV4Wsazk8zC2XpV9tAX9X/90dNWEGTFNPp39qUpLaRBqfki57hPPeWhoGHPQql4xCT6DOW6h1JFb92d9Wwpl6yRRcVdjmCNBaDyyg7mliKIavmHFEE0IToH+sAh0G8RK7gv8ckcpBKM7NZPtRisY/irDRbzlNDdayoPLOfdxd+5iRlnGX42ZsHA5Wq6uiRTTqksk40tTOiOXr3M75arAg9IkrLpDmTiyDZU8BEe0L8uUMLkjz7jEicy7Kxzqb36ulJ bob@example"
+	dummyECDSAPublicKey = "ecdsa-sha2-nistp384 AAAAE2VjZHNhLXNoYTItbmlzdHAzODQAAAAIbmlzdHAzODQAAABhBH9sujQtLC7gGsMaa2Qv49t9Xq4nYPGTqkNIiDj/636q8HQCUE+UPo7tD+DXs6H0Oy9ILm0XPt/fKPIrzrBazMHU1n2vwkovZ1eNz3dlXtHuiSGFhRzIAbIQ4pclFOnslQ== carol"
+)
+
+func TestParseSSHPublicKey(t *testing.T) {
+	type testCase struct {
+		key               string
+		keyType           string
+		bits              int
+		comment           string
+		fingerprintSHA256 string
+		fingerprintMD5    string
+	}
+	testCases := []testCase{
+		{
+			key:               dummySSHPublicKey,
+			keyType:           "ssh-ed25519",
+			bits:              256,
+			comment:           "alice@example",
+			fingerprintSHA256: "SHA256:afQKNg/jQVYu1E27uE9MKm7il1ni0XwVrT1a1W1yuPY",
+			fingerprintMD5:    "MD5:d9:60:01:9f:41:88:3e:db:7c:39:df:c0:71:01:4c:85",
+		},
+		{
+			key:               dummyRSAPublicKey,
+			keyType:           "ssh-rsa",
+			bits:              2048,
+			comment:           "bob@example",
+			fingerprintSHA256: "SHA256:IEZp89cldGBwhAyfv1XufTSp4+ahgNs9WHlQerNutU8",
+			fingerprintMD5:    "MD5:76:47:e0:a0:ae:54:68:f6:4f:7c:b6:f9:b2:ac:07:46",
+		},
+		{
+			key:               dummyECDSAPublicKey,
+			keyType:           "ecdsa-sha2-nistp384",
+			bits:              384,
+			comment:           "carol",
+			fingerprintSHA256: "SHA256:fVVm+KWf96MUStSRaIHjWN835YfOmPlS2cM2+uYc4aA",
+			fingerprintMD5:    "MD5:c6:24:da:89:bb:ba:c1:8e:82:1b:e7:99:59:6f:a1:3b",
+		},
+	}
+	for _, test := range testCases {
+		key, err := ParseSSHPublicKey(test.key + "\n")
+		assert.Nil(t, err, "ParseSSHPublicKey returned an error %v", err)
+		assert.Equal(t, test.keyType, key.Type)
+		assert.Equal(t, test.bits, key.Bits)
+		assert.Equal(t, test.comment, key.Comment)
+		assert.Equal(t, test.fingerprintSHA256, key.FingerprintSHA256())
+		assert.Equal(t, test.fingerprintMD5, key.FingerprintMD5())
+		assert.Equal(t, test.key, key.String())
+	}
+
+	key, err := ParseSSHPublicKey(`from="10.0.0.0/8",no-pty ` + dummySSHPublicKey)
+	assert.Nil(t, err, "ParseSSHPublicKey returned an error %v", err)
+	assert.Equal(t, dummySSHPublicKey, key.String())
+}
+
+func TestParseSSHPublicKey_Invalid(t *testing.T) {
+	smallRSAKey := "ssh-rsa " + base64.StdEncoding.EncodeToString(sshWireStrings("ssh-rsa", "\x01\x00\x01", string(make([]byte, 64))))
+	invalidKeys := []string{
+		"",
+		"example",
+		"ssh-ed25519",
+		"ssh-ed25519 not-base64!",
+		"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAILc2TvMRPqlC33XareGmg0/2rBknj+Pxyf8Tv4OCBLd9",
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILc2TvMRPqlC33XareGmg0/2rBknj+Pxyf8Tv4OC",
+		"ssh-ed25519 " + base64.StdEncoding.EncodeToString(sshWireStrings("ssh-ed25519", "short")),
+		smallRSAKey,
+		dummySSHPublicKey + "\n" + dummyRSAPublicKey,
+	}
+	for _, invalidKey := range invalidKeys {
+		_, err := ParseSSHPublicKey(invalidKey)
+		assert.NotNil(t, err, "ParseSSHPublicKey accepted %q", invalidKey)
+	}
+}
+
+func TestParseAuthorizedKeys(t *testing.T) {
+	data := "# team keys\n\n" + dummySSHPublicKey + "\n" + dummyRSAPublicKey + "\n"
+	keys, err := ParseAuthorizedKeys([]byte(data))
+	assert.Nil(t, err, "ParseAuthorizedKeys returned an error %v", err)
+	assert.Equal(t, 2, len(keys))
+	assert.Equal(t, "alice@example", keys[0].Comment)
+	assert.Equal(t, "bob@example", keys[1].Comment)
+
+	_, err = ParseAuthorizedKeys([]byte(dummySSHPublicKey + "\nexample\n"))
+	assert.EqualError(t, err, "line 2: SSH public key type example is unknown")
+}
+
+// sshWireStrings encodes strings in SSH wire format.
+func sshWireStrings(values ...string) []byte {
+	var data []byte
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint32(data, uint32(len(value)))
+		data = append(data, value...)
+	}
+	return data
+}
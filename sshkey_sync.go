@@ -0,0 +1,208 @@
+package gsclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SshkeySyncOptions represents the options of an SSH key sync.
+type SshkeySyncOptions struct {
+	// Label added to created and updated SSH keys. SSH keys which are not in the desired set are only deleted
+	// if they carry this label. Leave it empty to never delete SSH keys.
+	ManagedLabel string
+
+	// If true, the changes are only reported, but not applied.
+	DryRun bool
+}
+
+// SshkeySyncReport represents the result of an SSH key sync. All lists contain SSH key names.
+type SshkeySyncReport struct {
+	// SSH keys which were created.
+	Created []string
+
+	// SSH keys whose public key or labels were updated.
+	Updated []string
+
+	// SSH keys which were deleted.
+	Deleted []string
+
+	// SSH keys which were already up to date.
+	Unchanged []string
+
+	// True if the changes were not applied.
+	DryRun bool
+}
+
+// LoadSSHPublicKeysFromDir reads the public keys of all .pub files in a directory.
+// The keys are named after their files without the .pub extension.
+func LoadSSHPublicKeysFromDir(dir string) (map[string]SSHPublicKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]SSHPublicKey)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseSSHPublicKey(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		keys[strings.TrimSuffix(filepath.Base(file), ".pub")] = key
+	}
+	return keys, nil
+}
+
+// LoadAuthorizedKeysFile reads the public keys of an authorized_keys file.
+// The keys are named after their comments, which must be present and unique.
+func LoadAuthorizedKeysFile(file string) (map[string]SSHPublicKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	parsedKeys, err := ParseAuthorizedKeys(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	keys := make(map[string]SSHPublicKey)
+	for _, key := range parsedKeys {
+		if key.Comment == "" {
+			return nil, fmt.Errorf("%s: key %s has no comment to name it", file, key.FingerprintSHA256())
+		}
+		if _, ok := keys[key.Comment]; ok {
+			return nil, fmt.Errorf("%s: several keys have the comment %s", file, key.Comment)
+		}
+		keys[key.Comment] = key
+	}
+	return keys, nil
+}
+
+// GetDuplicateSshkeys returns groups of SSH keys in the project which share the same public key.
+// SSH keys which cannot be parsed are ignored.
+func (c *Client) GetDuplicateSshkeys(ctx context.Context) ([][]Sshkey, error) {
+	sshkeys, err := c.GetSshkeyList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(sshkeys, func(i, j int) bool {
+		return sshkeys[i].Properties.Name < sshkeys[j].Properties.Name
+	})
+	var fingerprints []string
+	groups := make(map[string][]Sshkey)
+	for _, sshkey := range sshkeys {
+		key, err := ParseSSHPublicKey(sshkey.Properties.Sshkey)
+		if err != nil {
+			continue
+		}
+		fingerprint := key.FingerprintSHA256()
+		if _, ok := groups[fingerprint]; !ok {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+		groups[fingerprint] = append(groups[fingerprint], sshkey)
+	}
+	var duplicates [][]Sshkey
+	for _, fingerprint := range fingerprints {
+		if len(groups[fingerprint]) > 1 {
+			duplicates = append(duplicates, groups[fingerprint])
+		}
+	}
+	return duplicates, nil
+}
+
+// SyncSshkeys reconciles the SSH keys of the project with a desired set of named public keys,
+// e.g. loaded by LoadSSHPublicKeysFromDir or LoadAuthorizedKeysFile. SSH keys are matched by name.
+func (c *Client) SyncSshkeys(ctx context.Context, keys map[string]SSHPublicKey, opts SshkeySyncOptions) (SshkeySyncReport, error) {
+	report := SshkeySyncReport{DryRun: opts.DryRun}
+	sshkeys, err := c.GetSshkeyList(ctx)
+	if err != nil {
+		return report, err
+	}
+	existing := make(map[string]SshkeyProperties)
+	for _, sshkey := range sshkeys {
+		name := sshkey.Properties.Name
+		if _, ok := existing[name]; ok {
+			return report, fmt.Errorf("several SSH keys are named %s", name)
+		}
+		existing[name] = sshkey.Properties
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		desiredKey := keys[name].String()
+		properties, ok := existing[name]
+		if !ok {
+			report.Created = append(report.Created, name)
+			if opts.DryRun {
+				continue
+			}
+			var labels []string
+			if opts.ManagedLabel != "" {
+				labels = []string{opts.ManagedLabel}
+			}
+			_, err = c.CreateSshkey(ctx, SshkeyCreateRequest{
+				Name:   name,
+				Sshkey: desiredKey,
+				Labels: labels,
+			})
+			if err != nil {
+				return report, fmt.Errorf("creating SSH key %s failed: %v", name, err)
+			}
+			continue
+		}
+		var update SshkeyUpdateRequest
+		currentKey, parseErr := ParseSSHPublicKey(properties.Sshkey)
+		if parseErr != nil || currentKey.String() != desiredKey {
+			update.Sshkey = desiredKey
+		}
+		if opts.ManagedLabel != "" && !hasLabel(properties.Labels, opts.ManagedLabel) {
+			labels := append(append([]string{}, properties.Labels...), opts.ManagedLabel)
+			update.Labels = &labels
+		}
+		if update.Sshkey == "" && update.Labels == nil {
+			report.Unchanged = append(report.Unchanged, name)
+			continue
+		}
+		report.Updated = append(report.Updated, name)
+		if opts.DryRun {
+			continue
+		}
+		err = c.UpdateSshkey(ctx, properties.ObjectUUID, update)
+		if err != nil {
+			return report, fmt.Errorf("updating SSH key %s failed: %v", name, err)
+		}
+	}
+
+	if opts.ManagedLabel == "" {
+		return report, nil
+	}
+	var obsolete []SshkeyProperties
+	for name, properties := range existing {
+		if _, ok := keys[name]; !ok && hasLabel(properties.Labels, opts.ManagedLabel) {
+			obsolete = append(obsolete, properties)
+		}
+	}
+	sort.Slice(obsolete, func(i, j int) bool {
+		return obsolete[i].Name < obsolete[j].Name
+	})
+	for _, properties := range obsolete {
+		report.Deleted = append(report.Deleted, properties.Name)
+		if opts.DryRun {
+			continue
+		}
+		err = c.DeleteSshkey(ctx, properties.ObjectUUID)
+		if err != nil {
+			return report, fmt.Errorf("deleting SSH key %s failed: %v", properties.Name, err)
+		}
+	}
+	return report, nil
+}
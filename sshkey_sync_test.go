@@ -0,0 +1,158 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSSHPublicKeysFromDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "alice.pub"), []byte(dummySSHPublicKey+"\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "bob.pub"), []byte(dummyRSAPublicKey), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "README"), []byte("team keys"), 0644))
+	keys, err := LoadSSHPublicKeysFromDir(dir)
+	assert.Nil(t, err, "LoadSSHPublicKeysFromDir returned an error %v", err)
+	assert.Equal(t, 2, len(keys))
+	assert.Equal(t, dummySSHPublicKey, keys["alice"].String())
+	assert.Equal(t, dummyRSAPublicKey, keys["bob"].String())
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "carol.pub"), []byte("example"), 0644))
+	_, err = LoadSSHPublicKeysFromDir(dir)
+	assert.NotNil(t, err)
+}
+
+func TestLoadAuthorizedKeysFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "authorized_keys")
+	assert.Nil(t, os.WriteFile(file, []byte(dummySSHPublicKey+"\n"+dummyECDSAPublicKey+"\n"), 0644))
+	keys, err := LoadAuthorizedKeysFile(file)
+	assert.Nil(t, err, "LoadAuthorizedKeysFile returned an error %v", err)
+	assert.Equal(t, 2, len(keys))
+	assert.Equal(t, dummySSHPublicKey, keys["alice@example"].String())
+	assert.Equal(t, dummyECDSAPublicKey, keys["carol"].String())
+
+	assert.Nil(t, os.WriteFile(file, []byte(dummySSHPublicKey+"\n"+dummySSHPublicKey+"\n"), 0644))
+	_, err = LoadAuthorizedKeysFile(file)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.WriteFile(file, []byte(strings.TrimSuffix(dummySSHPublicKey, " alice@example")), 0644))
+	_, err = LoadAuthorizedKeysFile(file)
+	assert.NotNil(t, err)
+}
+
+func TestClient_GetDuplicateSshkeys(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	mux.HandleFunc(apiSshkeyBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareSshkeySyncListHTTPGet([]SshkeyProperties{
+			{Name: "alice", ObjectUUID: dummyUUID, Sshkey: dummySSHPublicKey},
+			{Name: "bob", ObjectUUID: "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c", Sshkey: dummyRSAPublicKey},
+			{Name: "alice-laptop", ObjectUUID: "4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d", Sshkey: strings.TrimSuffix(dummySSHPublicKey, " alice@example")},
+			{Name: "example", ObjectUUID: "5c6d9b4e-3f4a-4b7c-8d1e-2f3a4b5c6d7e", Sshkey: "example"},
+		}))
+	})
+	duplicates, err := client.GetDuplicateSshkeys(emptyCtx)
+	assert.Nil(t, err, "GetDuplicateSshkeys returned an error %v", err)
+	assert.Equal(t, 1, len(duplicates))
+	var names []string
+	for _, sshkey := range duplicates[0] {
+		names = append(names, sshkey.Properties.Name)
+	}
+	assert.Equal(t, []string{"alice", "alice-laptop"}, names)
+}
+
+func TestClient_SyncSshkeys(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var created []SshkeyCreateRequest
+	updated := make(map[string]SshkeyUpdateRequest)
+	var deleted []string
+	mux.HandleFunc(apiSshkeyBase, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, prepareSshkeySyncListHTTPGet([]SshkeyProperties{
+				{Name: "alice", ObjectUUID: dummyUUID, Sshkey: dummySSHPublicKey, Labels: []string{"team"}},
+				{Name: "bob", ObjectUUID: "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c", Sshkey: "example"},
+				{Name: "dave", ObjectUUID: "4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d", Sshkey: dummyECDSAPublicKey, Labels: []string{"team"}},
+				{Name: "eve", ObjectUUID: "5c6d9b4e-3f4a-4b7c-8d1e-2f3a4b5c6d7e", Sshkey: dummyECDSAPublicKey},
+			}))
+		case http.MethodPost:
+			var body SshkeyCreateRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+			created = append(created, body)
+			fmt.Fprint(w, prepareSshkeyCreateResponse())
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+	mux.HandleFunc(apiSshkeyBase+"/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		id := strings.TrimPrefix(r.URL.Path, apiSshkeyBase+"/")
+		switch r.Method {
+		case http.MethodPatch:
+			var body SshkeyUpdateRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+			updated[id] = body
+		case http.MethodDelete:
+			deleted = append(deleted, id)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+	keys := make(map[string]SSHPublicKey)
+	for name, key := range map[string]string{"alice": dummySSHPublicKey, "bob": dummyRSAPublicKey, "carol": dummyECDSAPublicKey} {
+		parsed, err := ParseSSHPublicKey(key)
+		assert.Nil(t, err, "ParseSSHPublicKey returned an error %v", err)
+		keys[name] = parsed
+	}
+	opts := SshkeySyncOptions{ManagedLabel: "team", DryRun: true}
+	expected := SshkeySyncReport{
+		Created:   []string{"carol"},
+		Updated:   []string{"bob"},
+		Deleted:   []string{"dave"},
+		Unchanged: []string{"alice"},
+		DryRun:    true,
+	}
+	report, err := client.SyncSshkeys(emptyCtx, keys, opts)
+	assert.Nil(t, err, "SyncSshkeys returned an error %v", err)
+	assert.Equal(t, expected, report)
+	assert.Nil(t, created)
+	assert.Empty(t, updated)
+	assert.Nil(t, deleted)
+
+	opts.DryRun = false
+	expected.DryRun = false
+	report, err = client.SyncSshkeys(emptyCtx, keys, opts)
+	assert.Nil(t, err, "SyncSshkeys returned an error %v", err)
+	assert.Equal(t, expected, report)
+	assert.Equal(t, []SshkeyCreateRequest{{Name: "carol", Sshkey: dummyECDSAPublicKey, Labels: []string{"team"}}}, created)
+	bobLabels := []string{"team"}
+	assert.Equal(t, map[string]SshkeyUpdateRequest{
+		"3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c": {Sshkey: dummyRSAPublicKey, Labels: &bobLabels},
+	}, updated)
+	assert.Equal(t, []string{"4b5c8a3d-2e3f-4a6b-9c0d-1e2f3a4b5c6d"}, deleted)
+
+	// Without a managed label, no SSH key is deleted.
+	deleted = nil
+	report, err = client.SyncSshkeys(emptyCtx, keys, SshkeySyncOptions{DryRun: true})
+	assert.Nil(t, err, "SyncSshkeys returned an error %v", err)
+	assert.Nil(t, report.Deleted)
+}
+
+func prepareSshkeySyncListHTTPGet(sshkeys []SshkeyProperties) string {
+	list := SshkeyList{List: make(map[string]SshkeyProperties)}
+	for _, properties := range sshkeys {
+		list.List[properties.ObjectUUID] = properties
+	}
+	res, _ := json.Marshal(list)
+	return string(res)
+}
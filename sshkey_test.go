@@ -25,6 +25,22 @@ func TestClient_GetSshkeyList(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf("[%v]", getMockSshkey("active")), fmt.Sprintf("%v", res))
 }
 
+func TestClient_GetSshPublicKeysByName(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	mux.HandleFunc(apiSshkeyBase, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodGet, request.Method)
+		writer.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(writer, prepareSshkeyListHTTPGet())
+	})
+	keys, err := client.GetSshPublicKeysByName(emptyCtx, "test")
+	assert.Nil(t, err, "GetSshPublicKeysByName returned an error %v", err)
+	assert.Equal(t, []string{"example"}, keys)
+
+	_, err = client.GetSshPublicKeysByName(emptyCtx, "test", "missing")
+	assert.NotNil(t, err)
+}
+
 func TestClient_GetSshkey(t *testing.T) {
 	server, client, mux := setupTestClient(true)
 	defer server.Close()
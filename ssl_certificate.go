@@ -13,6 +13,8 @@ type SSLCertificateOperator interface {
 	GetSSLCertificate(ctx context.Context, id string) (SSLCertificate, error)
 	CreateSSLCertificate(ctx context.Context, body SSLCertificateCreateRequest) (CreateResponse, error)
 	DeleteSSLCertificate(ctx context.Context, id string) error
+	RotateSSLCertificate(ctx context.Context, oldID string, body SSLCertificateCreateRequest) (SSLCertificateRotationResponse, error)
+	GetExpiringSSLCertificates(ctx context.Context, days int) ([]SSLCertificate, error)
 }
 
 // SSLCertificateList holds a list of SSL certificates.
@@ -0,0 +1,154 @@
+package gsclient
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SSLCertificateBundle holds a parsed PEM bundle of a SSL certificate.
+type SSLCertificateBundle struct {
+	// The private key of the SSL certificate.
+	PrivateKey crypto.Signer
+
+	// The leaf certificate.
+	Leaf *x509.Certificate
+
+	// The intermediate certificates, ordered from the leaf's issuer up to the certificate authority.
+	Chain []*x509.Certificate
+}
+
+// ParseSSLCertificateBundle parses the PEM-formatted private key, leaf certificate and (optional)
+// certificate chain of a SSL certificate.
+func ParseSSLCertificateBundle(privateKeyPEM, leafCertificatePEM, certificateChainPEM string) (SSLCertificateBundle, error) {
+	var bundle SSLCertificateBundle
+	privateKey, err := parsePEMPrivateKey(privateKeyPEM)
+	if err != nil {
+		return bundle, err
+	}
+	leafs, err := parsePEMCertificates(leafCertificatePEM)
+	if err != nil {
+		return bundle, err
+	}
+	if len(leafs) != 1 {
+		return bundle, errors.New("leaf certificate must contain exactly one certificate")
+	}
+	chain, err := parsePEMCertificates(certificateChainPEM)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.PrivateKey = privateKey
+	bundle.Leaf = leafs[0]
+	bundle.Chain = chain
+	return bundle, nil
+}
+
+// ValidateSSLCertificateCreateRequest parses the PEM data of a SSL certificate create request
+// and validates it locally before it is sent to the API. See SSLCertificateBundle.Validate.
+func ValidateSSLCertificateCreateRequest(body SSLCertificateCreateRequest) (SSLCertificateBundle, error) {
+	bundle, err := ParseSSLCertificateBundle(body.PrivateKey, body.LeafCertificate, body.CertificateChain)
+	if err != nil {
+		return bundle, err
+	}
+	return bundle, bundle.Validate()
+}
+
+// Validate checks that the private key matches the leaf certificate, the leaf certificate
+// has not expired, and the certificate chain is in the right order (each certificate is signed
+// by the next one).
+func (b SSLCertificateBundle) Validate() error {
+	if b.PrivateKey == nil || b.Leaf == nil {
+		return errors.New("private key and leaf certificate are required")
+	}
+	publicKey, ok := b.PrivateKey.Public().(interface {
+		Equal(x crypto.PublicKey) bool
+	})
+	if !ok || !publicKey.Equal(b.Leaf.PublicKey) {
+		return errors.New("private key does not match the leaf certificate")
+	}
+	if time.Now().After(b.Leaf.NotAfter) {
+		return fmt.Errorf("leaf certificate expired on %s", b.Leaf.NotAfter.Format(gsTimeLayout))
+	}
+	cert := b.Leaf
+	for i, issuer := range b.Chain {
+		if err := cert.CheckSignatureFrom(issuer); err != nil {
+			return fmt.Errorf("certificate chain is not in order: certificate %d of the chain is not the issuer of the certificate before it: %v", i+1, err)
+		}
+		cert = issuer
+	}
+	return nil
+}
+
+// SHA256Fingerprint returns the hex-encoded SHA-256 fingerprint of the leaf certificate.
+func (b SSLCertificateBundle) SHA256Fingerprint() string {
+	if b.Leaf == nil {
+		return ""
+	}
+	sum := sha256.Sum256(b.Leaf.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchesFingerprints returns true if the SHA-256 fingerprint of the leaf certificate
+// equals the one reported by the API. Case and colon separators are ignored.
+func (b SSLCertificateBundle) MatchesFingerprints(fingerprints FingerprintProperties) bool {
+	return normalizeFingerprint(fingerprints.SHA256) == b.SHA256Fingerprint()
+}
+
+// normalizeFingerprint converts a fingerprint to lowercase hex without separators.
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
+
+// parsePEMCertificates parses all PEM-encoded certificates of a string.
+func parsePEMCertificates(data string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 && strings.TrimSpace(data) != "" {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	return certs, nil
+}
+
+// parsePEMPrivateKey parses a PEM-encoded PKCS #1, PKCS #8 or EC private key.
+func parsePEMPrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM-encoded private key found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("private key is neither a PKCS #1, PKCS #8 nor an EC private key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
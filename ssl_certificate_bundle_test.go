@@ -0,0 +1,124 @@
+package gsclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSSLCertificateBundle(t *testing.T) {
+	caKey, caCert := generateMockCertificate(t, "ca", "", "")
+	leafKey, leafCert := generateMockCertificate(t, "www.example.com", caKey, caCert)
+	_, otherCert := generateMockCertificate(t, "other", "", "")
+	testCases := []struct {
+		privateKey string
+		leaf       string
+		chain      string
+		isFailed   bool
+	}{
+		{
+			privateKey: leafKey,
+			leaf:       leafCert,
+			chain:      caCert,
+			isFailed:   false,
+		},
+		{
+			privateKey: leafKey,
+			leaf:       leafCert,
+			chain:      "",
+			isFailed:   false,
+		},
+		{
+			privateKey: caKey,
+			leaf:       leafCert,
+			chain:      caCert,
+			isFailed:   true,
+		},
+		{
+			privateKey: leafKey,
+			leaf:       leafCert,
+			chain:      otherCert + caCert,
+			isFailed:   true,
+		},
+		{
+			privateKey: leafKey,
+			leaf:       leafCert + caCert,
+			chain:      "",
+			isFailed:   true,
+		},
+		{
+			privateKey: "invalid",
+			leaf:       leafCert,
+			chain:      "",
+			isFailed:   true,
+		},
+	}
+	for _, test := range testCases {
+		_, err := ValidateSSLCertificateCreateRequest(SSLCertificateCreateRequest{
+			Name:             "test",
+			PrivateKey:       test.privateKey,
+			LeafCertificate:  test.leaf,
+			CertificateChain: test.chain,
+		})
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "ValidateSSLCertificateCreateRequest returned an error %v", err)
+		}
+	}
+}
+
+func TestSSLCertificateBundle_MatchesFingerprints(t *testing.T) {
+	key, cert := generateMockCertificate(t, "www.example.com", "", "")
+	bundle, err := ParseSSLCertificateBundle(key, cert, "")
+	assert.Nil(t, err, "ParseSSLCertificateBundle returned an error %v", err)
+	fingerprint := bundle.SHA256Fingerprint()
+	assert.Equal(t, 64, len(fingerprint))
+
+	var apiFingerprint []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		apiFingerprint = append(apiFingerprint, strings.ToUpper(fingerprint[i:i+2]))
+	}
+	assert.True(t, bundle.MatchesFingerprints(FingerprintProperties{SHA256: strings.Join(apiFingerprint, ":")}))
+	assert.False(t, bundle.MatchesFingerprints(getMockSSLCertificate("active").Properties.Fingerprints))
+}
+
+// generateMockCertificate generates a PEM-encoded private key and certificate.
+// The certificate is self-signed if no parent is given.
+func generateMockCertificate(t *testing.T, commonName string, parentKeyPEM, parentCertPEM string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  parentCertPEM == "",
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	parent := template
+	var signer interface{} = key
+	if parentCertPEM != "" {
+		bundle, err := ParseSSLCertificateBundle(parentKeyPEM, parentCertPEM, "")
+		assert.Nil(t, err)
+		parent = bundle.Leaf
+		signer = bundle.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	assert.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return string(keyPEM), string(certPEM)
+}
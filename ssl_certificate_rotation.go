@@ -0,0 +1,150 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SSLCertificateRotationResponse represents a response for rotating a SSL certificate.
+type SSLCertificateRotationResponse struct {
+	// UUID of the new SSL certificate.
+	CertificateUUID string
+
+	// UUIDs of the load balancers whose forwarding rules have been repointed to the new SSL certificate.
+	LoadBalancerUUIDs []string
+}
+
+// RotateSSLCertificate replaces a SSL certificate with a new one. It validates the new certificate locally,
+// uploads it, checks that its SHA-256 fingerprint matches the one computed by the API, repoints every
+// load balancer forwarding rule referencing the old certificate, waits for the load balancers to become active,
+// and finally deletes the old certificate.
+//
+// If repointing a load balancer fails, the already repointed load balancers are restored and the new
+// certificate is removed.
+func (c *Client) RotateSSLCertificate(ctx context.Context, oldID string, body SSLCertificateCreateRequest) (SSLCertificateRotationResponse, error) {
+	var response SSLCertificateRotationResponse
+	if !isValidUUID(oldID) {
+		return response, errors.New("'oldID' is invalid")
+	}
+	bundle, err := ValidateSSLCertificateCreateRequest(body)
+	if err != nil {
+		return response, err
+	}
+	// Make sure the old certificate exists before uploading the new one.
+	if _, err = c.GetSSLCertificate(ctx, oldID); err != nil {
+		return response, err
+	}
+	lbs, err := c.GetLoadBalancerList(ctx)
+	if err != nil {
+		return response, err
+	}
+	created, err := c.CreateSSLCertificate(ctx, body)
+	if err != nil {
+		return response, err
+	}
+	response.CertificateUUID = created.ObjectUUID
+	newCert, err := c.GetSSLCertificate(ctx, created.ObjectUUID)
+	if err != nil {
+		return response, c.cleanupRotatedSSLCertificate(ctx, created.ObjectUUID, nil, err)
+	}
+	if !bundle.MatchesFingerprints(newCert.Properties.Fingerprints) {
+		err = fmt.Errorf("SHA-256 fingerprint of the uploaded certificate (%s) does not match the local one (%s)",
+			newCert.Properties.Fingerprints.SHA256, bundle.SHA256Fingerprint())
+		return response, c.cleanupRotatedSSLCertificate(ctx, created.ObjectUUID, nil, err)
+	}
+
+	var repointed []LoadBalancerProperties
+	for _, lb := range lbs {
+		rules, changed := repointForwardingRules(lb.Properties.ForwardingRules, oldID, created.ObjectUUID)
+		if !changed {
+			continue
+		}
+		req := newLoadBalancerUpdateRequest(lb.Properties, lb.Properties.BackendServers)
+		req.ForwardingRules = rules
+		err = c.UpdateLoadBalancer(ctx, lb.Properties.ObjectUUID, req)
+		if err != nil {
+			err = fmt.Errorf("repointing load balancer %s failed: %v", lb.Properties.ObjectUUID, err)
+			return response, c.cleanupRotatedSSLCertificate(ctx, created.ObjectUUID, repointed, err)
+		}
+		repointed = append(repointed, lb.Properties)
+		// The old certificate cannot be deleted before the load balancer has applied the new forwarding rules.
+		_, err = c.waitForLoadBalancerActive(ctx, lb.Properties.ObjectUUID)
+		if err != nil {
+			err = fmt.Errorf("waiting for load balancer %s failed: %v", lb.Properties.ObjectUUID, err)
+			return response, c.cleanupRotatedSSLCertificate(ctx, created.ObjectUUID, repointed, err)
+		}
+		response.LoadBalancerUUIDs = append(response.LoadBalancerUUIDs, lb.Properties.ObjectUUID)
+	}
+	return response, c.DeleteSSLCertificate(ctx, oldID)
+}
+
+// cleanupRotatedSSLCertificate restores the load balancers repointed during a failed rotation,
+// removes the new SSL certificate and returns the error which caused the failure.
+func (c *Client) cleanupRotatedSSLCertificate(ctx context.Context, newID string, repointed []LoadBalancerProperties, cause error) error {
+	// Clean up even if the rotation has been cancelled.
+	if ctx.Err() != nil {
+		ctx = context.Background()
+	}
+	errs := []error{cause}
+	isRestored := true
+	for _, lb := range repointed {
+		err := c.UpdateLoadBalancer(ctx, lb.ObjectUUID, newLoadBalancerUpdateRequest(lb, lb.BackendServers))
+		if err == nil {
+			// Like the old one, the new certificate cannot be removed before the load balancer has applied the change.
+			_, err = c.waitForLoadBalancerActive(ctx, lb.ObjectUUID)
+		}
+		if err != nil {
+			isRestored = false
+			errs = append(errs, fmt.Errorf("restoring load balancer %s failed: %v", lb.ObjectUUID, err))
+		}
+	}
+	// The new certificate cannot be removed while it is still in use.
+	if isRestored {
+		if err := c.DeleteSSLCertificate(ctx, newID); err != nil {
+			errs = append(errs, fmt.Errorf("removing certificate %s failed: %v", newID, err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// repointForwardingRules returns a copy of the forwarding rules with every reference to
+// the old certificate replaced by the new one, and whether any rule has been changed.
+func repointForwardingRules(rules []ForwardingRule, oldID, newID string) ([]ForwardingRule, bool) {
+	changed := false
+	repointed := make([]ForwardingRule, len(rules))
+	for i, rule := range rules {
+		if rule.CertificateUUID == oldID {
+			rule.CertificateUUID = newID
+			changed = true
+		}
+		repointed[i] = rule
+	}
+	return repointed, changed
+}
+
+// GetExpiringSSLCertificates returns the SSL certificates which expire within the given number of days
+// (including the already expired ones), sorted by their expiration date.
+func (c *Client) GetExpiringSSLCertificates(ctx context.Context, days int) ([]SSLCertificate, error) {
+	if days < 0 {
+		return nil, errors.New("'days' must not be negative")
+	}
+	certs, err := c.GetSSLCertificateList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().AddDate(0, 0, days)
+	var expiring []SSLCertificate
+	for _, cert := range certs {
+		notValidAfter := cert.Properties.NotValidAfter.Time
+		if !notValidAfter.IsZero() && notValidAfter.Before(deadline) {
+			expiring = append(expiring, cert)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].Properties.NotValidAfter.Before(expiring[j].Properties.NotValidAfter.Time)
+	})
+	return expiring, nil
+}
@@ -0,0 +1,182 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RotateSSLCertificate(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	newCertUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	key, cert := generateMockCertificate(t, "www.example.com", "", "")
+	bundle, _ := ParseSSLCertificateBundle(key, cert, "")
+	var isFingerprintMismatched, isLBUpdateFailed, isLBWaitFailed bool
+	var lbUpdates []LoadBalancerUpdateRequest
+	var deleted, events []string
+	var isLBBusy bool
+
+	mux.HandleFunc(apiSSLCertificateBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, newCertUUID, dummyRequestUUID)
+	})
+	mux.HandleFunc(path.Join(apiSSLCertificateBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, dummyUUID)
+			events = append(events, "old certificate deleted")
+			return
+		}
+		fmt.Fprint(w, prepareSSLCertificateHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiSSLCertificateBase, newCertUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, newCertUUID)
+			events = append(events, "new certificate deleted")
+			return
+		}
+		newCert := getMockSSLCertificate("active")
+		newCert.Properties.ObjectUUID = newCertUUID
+		newCert.Properties.Fingerprints.SHA256 = bundle.SHA256Fingerprint()
+		if isFingerprintMismatched {
+			newCert.Properties.Fingerprints.SHA256 = "00"
+		}
+		res, _ := json.Marshal(newCert)
+		fmt.Fprint(w, string(res))
+	})
+	lb := getMockLoadbalancer("active")
+	lb.Properties.ForwardingRules = append(lb.Properties.ForwardingRules, ForwardingRule{
+		CertificateUUID: dummyUUID,
+		ListenPort:      443,
+		Mode:            "http",
+		TargetPort:      8000,
+	})
+	mux.HandleFunc(apiLoadBalancerBase, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(lb.Properties)
+		fmt.Fprintf(w, `{"loadbalancers": {"%s": %s}}`, dummyUUID, string(res))
+	})
+	mux.HandleFunc(path.Join(apiLoadBalancerBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodGet {
+			if isLBWaitFailed {
+				isLBWaitFailed = false
+				w.WriteHeader(400)
+				return
+			}
+			// The load balancer is busy on the first GET after an update.
+			status := "active"
+			if isLBBusy {
+				status = "in-provisioning"
+				isLBBusy = false
+			} else {
+				events = append(events, "lb active")
+			}
+			fmt.Fprint(w, prepareLoadBalancerHTTPGetResponse(status))
+			return
+		}
+		assert.Equal(t, http.MethodPatch, r.Method)
+		isLBBusy = true
+		var body LoadBalancerUpdateRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		lbUpdates = append(lbUpdates, body)
+		if isLBUpdateFailed {
+			w.WriteHeader(400)
+		}
+	})
+	testCases := []struct {
+		isFingerprintMismatched bool
+		isLBUpdateFailed        bool
+		isLBWaitFailed          bool
+		expectedDeleted         []string
+		expectedEvents          []string
+	}{
+		{
+			expectedDeleted: []string{dummyUUID},
+		},
+		{
+			isFingerprintMismatched: true,
+			expectedDeleted:         []string{newCertUUID},
+		},
+		{
+			isLBUpdateFailed: true,
+			expectedDeleted:  []string{newCertUUID},
+			expectedEvents:   []string{"new certificate deleted"},
+		},
+		{
+			// The new certificate is removed after the restored load balancer is active again.
+			isLBWaitFailed:  true,
+			expectedDeleted: []string{newCertUUID},
+			expectedEvents:  []string{"lb active", "new certificate deleted"},
+		},
+	}
+	for _, test := range testCases {
+		isFingerprintMismatched = test.isFingerprintMismatched
+		isLBUpdateFailed = test.isLBUpdateFailed
+		isLBWaitFailed = test.isLBWaitFailed
+		isLBBusy = false
+		lbUpdates = nil
+		deleted = nil
+		events = nil
+		response, err := client.RotateSSLCertificate(emptyCtx, dummyUUID, SSLCertificateCreateRequest{
+			Name:            "test",
+			PrivateKey:      key,
+			LeafCertificate: cert,
+		})
+		assert.Equal(t, test.expectedDeleted, deleted)
+		if test.isFingerprintMismatched || test.isLBUpdateFailed || test.isLBWaitFailed {
+			assert.NotNil(t, err)
+			if test.expectedEvents != nil {
+				assert.Equal(t, test.expectedEvents, events)
+			}
+			continue
+		}
+		assert.Nil(t, err, "RotateSSLCertificate returned an error %v", err)
+		assert.Equal(t, newCertUUID, response.CertificateUUID)
+		assert.Equal(t, []string{dummyUUID}, response.LoadBalancerUUIDs)
+		assert.Equal(t, 1, len(lbUpdates))
+		assert.Equal(t, []string{"lb active", "old certificate deleted"}, events)
+		assert.Equal(t, "", lbUpdates[0].ForwardingRules[0].CertificateUUID)
+		assert.Equal(t, newCertUUID, lbUpdates[0].ForwardingRules[1].CertificateUUID)
+	}
+	_, err := client.RotateSSLCertificate(emptyCtx, dummyUUID, SSLCertificateCreateRequest{Name: "test"})
+	assert.NotNil(t, err)
+}
+
+func TestClient_GetExpiringSSLCertificates(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	expiring := getMockSSLCertificate("active")
+	notExpiring := getMockSSLCertificate("active")
+	notExpiring.Properties.ObjectUUID = "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	notExpiring.Properties.NotValidAfter = GSTime{time.Now().AddDate(1, 0, 0).Truncate(time.Second).UTC()}
+	mux.HandleFunc(apiSSLCertificateBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		list := SSLCertificateList{List: map[string]SSLCertificateProperties{
+			expiring.Properties.ObjectUUID:    expiring.Properties,
+			notExpiring.Properties.ObjectUUID: notExpiring.Properties,
+		}}
+		res, _ := json.Marshal(list)
+		fmt.Fprint(w, string(res))
+	})
+	certs, err := client.GetExpiringSSLCertificates(emptyCtx, 30)
+	assert.Nil(t, err, "GetExpiringSSLCertificates returned an error %v", err)
+	assert.Equal(t, 1, len(certs))
+	assert.Equal(t, dummyUUID, certs[0].Properties.ObjectUUID)
+
+	certs, err = client.GetExpiringSSLCertificates(emptyCtx, 400)
+	assert.Nil(t, err, "GetExpiringSSLCertificates returned an error %v", err)
+	assert.Equal(t, 2, len(certs))
+
+	_, err = client.GetExpiringSSLCertificates(emptyCtx, -1)
+	assert.NotNil(t, err)
+}
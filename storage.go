@@ -19,6 +19,8 @@ type StorageOperator interface {
 	DeleteStorage(ctx context.Context, id string) error
 	GetDeletedStorages(ctx context.Context) ([]Storage, error)
 	GetStorageEventList(ctx context.Context, id string) ([]Event, error)
+	MigrateStorage(ctx context.Context, storageID, targetLocationUUID string, opts StorageMigrationOptions) (StorageMigrationResponse, error)
+	ResizeStorage(ctx context.Context, id string, body StorageResizeRequest) (StorageResizeResponse, error)
 }
 
 // StorageList holds a list of storages.
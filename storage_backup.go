@@ -12,6 +12,8 @@ type StorageBackupOperator interface {
 	GetStorageBackupList(ctx context.Context, id string) ([]StorageBackup, error)
 	DeleteStorageBackup(ctx context.Context, storageID, backupID string) error
 	RollbackStorageBackup(ctx context.Context, storageID, backupID string, body StorageRollbackRequest) error
+	RestoreStorageBackup(ctx context.Context, storageID string, body StorageBackupRestoreRequest) (StorageBackupRestoreResponse, error)
+	GetStorageBackupCatalogue(ctx context.Context) ([]StorageBackupCatalogueEntry, error)
 }
 
 // StorageBackupList holds of a list of storage backups.
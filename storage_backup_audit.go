@@ -0,0 +1,158 @@
+package gsclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BackupComplianceFindingType represents the type of a backup compliance finding.
+type BackupComplianceFindingType string
+
+// All available backup compliance finding types.
+const (
+	// The storage has neither an active backup schedule nor a snapshot schedule.
+	NoActiveScheduleFinding BackupComplianceFindingType = "no-active-schedule"
+
+	// The next runtime of a schedule is in the past.
+	OverdueScheduleFinding BackupComplianceFindingType = "overdue-schedule"
+
+	// A schedule keeps fewer backups or snapshots than required by the policy.
+	InsufficientRetentionFinding BackupComplianceFindingType = "insufficient-retention"
+
+	// A backup schedule stores the backups in the same location as the storage.
+	SameLocationBackupFinding BackupComplianceFindingType = "same-location-backup"
+)
+
+// BackupCompliancePolicy represents the requirements checked by AuditBackupCompliance.
+type BackupCompliancePolicy struct {
+	// Minimum number of backups an active backup schedule has to keep. 0 disables the check.
+	MinKeepBackups int
+
+	// Minimum number of snapshots a snapshot schedule has to keep. 0 disables the check.
+	MinKeepSnapshots int
+
+	// Time a schedule's next runtime may be in the past before it is reported as overdue.
+	OverdueGracePeriod time.Duration
+
+	// If true, backups stored in the same location as the storage are reported.
+	RequireOffsiteBackups bool
+}
+
+// BackupComplianceFinding represents a violation of a backup compliance policy.
+type BackupComplianceFinding struct {
+	// The type of the finding.
+	Type BackupComplianceFindingType
+
+	// The UUID of the storage.
+	StorageUUID string
+
+	// The name of the storage.
+	StorageName string
+
+	// The UUID of the backup or snapshot schedule. Empty if the finding concerns the storage itself.
+	ScheduleUUID string
+
+	// A human-readable description of the finding.
+	Message string
+}
+
+// BackupComplianceReport represents the result of a backup compliance audit.
+type BackupComplianceReport struct {
+	// The time of the audit.
+	AuditTime time.Time
+
+	// Number of audited storages.
+	StorageCount int
+
+	// The findings, grouped by storage.
+	Findings []BackupComplianceFinding
+}
+
+// IsCompliant returns true if the audit has no findings.
+func (r BackupComplianceReport) IsCompliant() bool {
+	return len(r.Findings) == 0
+}
+
+// AuditBackupCompliance walks all storages with their backup and snapshot schedules and reports
+// violations of a backup compliance policy.
+func (c *Client) AuditBackupCompliance(ctx context.Context, policy BackupCompliancePolicy) (BackupComplianceReport, error) {
+	report := BackupComplianceReport{AuditTime: time.Now().UTC()}
+	overdueTime := report.AuditTime.Add(-policy.OverdueGracePeriod)
+	storages, err := c.GetStorageList(ctx)
+	if err != nil {
+		return report, err
+	}
+	sort.SliceStable(storages, func(i, j int) bool {
+		return storages[i].Properties.Name < storages[j].Properties.Name
+	})
+	backupCenters := make(map[string]string)
+	for _, storage := range storages {
+		report.StorageCount++
+		addFinding := func(findingType BackupComplianceFindingType, scheduleUUID, message string) {
+			report.Findings = append(report.Findings, BackupComplianceFinding{
+				Type:         findingType,
+				StorageUUID:  storage.Properties.ObjectUUID,
+				StorageName:  storage.Properties.Name,
+				ScheduleUUID: scheduleUUID,
+				Message:      message,
+			})
+		}
+		backupSchedules, err := c.GetStorageBackupScheduleList(ctx, storage.Properties.ObjectUUID)
+		if err != nil {
+			return report, err
+		}
+		snapshotSchedules, err := c.GetStorageSnapshotScheduleList(ctx, storage.Properties.ObjectUUID)
+		if err != nil {
+			return report, err
+		}
+
+		hasActiveSchedule := len(snapshotSchedules) > 0
+		for _, schedule := range backupSchedules {
+			properties := schedule.Properties
+			if !properties.Active {
+				continue
+			}
+			hasActiveSchedule = true
+			if properties.NextRuntime.Before(overdueTime) {
+				addFinding(OverdueScheduleFinding, properties.ObjectUUID,
+					fmt.Sprintf("backup schedule %s was due at %s", properties.Name, properties.NextRuntime.Format(time.RFC3339)))
+			}
+			if properties.KeepBackups < policy.MinKeepBackups {
+				addFinding(InsufficientRetentionFinding, properties.ObjectUUID,
+					fmt.Sprintf("backup schedule %s keeps %d backups, at least %d are required", properties.Name, properties.KeepBackups, policy.MinKeepBackups))
+			}
+			if policy.RequireOffsiteBackups && properties.BackupLocationUUID != "" {
+				backupCenter, ok := backupCenters[storage.Properties.LocationUUID]
+				if !ok {
+					location, err := c.GetLocation(ctx, storage.Properties.LocationUUID)
+					if err != nil {
+						return report, err
+					}
+					backupCenter = location.Properties.Features.BackupCenterLocationUUID
+					backupCenters[storage.Properties.LocationUUID] = backupCenter
+				}
+				if properties.BackupLocationUUID == storage.Properties.LocationUUID || properties.BackupLocationUUID == backupCenter {
+					addFinding(SameLocationBackupFinding, properties.ObjectUUID,
+						fmt.Sprintf("backup schedule %s stores backups in the storage's location %s", properties.Name, storage.Properties.LocationName))
+				}
+			}
+		}
+		for _, schedule := range snapshotSchedules {
+			properties := schedule.Properties
+			if properties.NextRuntime.Before(overdueTime) {
+				addFinding(OverdueScheduleFinding, properties.ObjectUUID,
+					fmt.Sprintf("snapshot schedule %s was due at %s", properties.Name, properties.NextRuntime.Format(time.RFC3339)))
+			}
+			if properties.KeepSnapshots < policy.MinKeepSnapshots {
+				addFinding(InsufficientRetentionFinding, properties.ObjectUUID,
+					fmt.Sprintf("snapshot schedule %s keeps %d snapshots, at least %d are required", properties.Name, properties.KeepSnapshots, policy.MinKeepSnapshots))
+			}
+		}
+		if !hasActiveSchedule {
+			addFinding(NoActiveScheduleFinding, "", "storage has no active backup or snapshot schedule")
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,106 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_AuditBackupCompliance(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	unprotectedUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	backupCenterUUID := "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e"
+	nextRuntime := GSTime{time.Now().Add(time.Hour).Truncate(time.Second).UTC()}
+	mux.HandleFunc(apiStorageBase, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		protected := getMockStorage("active")
+		protected.Properties.Name = "a"
+		unprotected := getMockStorage("active")
+		unprotected.Properties.ObjectUUID = unprotectedUUID
+		unprotected.Properties.Name = "b"
+		res, _ := json.Marshal(StorageList{List: map[string]StorageProperties{
+			dummyUUID:       protected.Properties,
+			unprotectedUUID: unprotected.Properties,
+		}})
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiLocationBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		location := getMockLocation()
+		location.Properties.Features.BackupCenterLocationUUID = backupCenterUUID
+		res, _ := json.Marshal(location)
+		fmt.Fprint(w, string(res))
+	})
+	for _, storageID := range []string{dummyUUID, unprotectedUUID} {
+		storageID := storageID
+		mux.HandleFunc(path.Join(apiStorageBase, storageID, "backup_schedules"), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			list := StorageBackupScheduleList{List: map[string]StorageBackupScheduleProperties{}}
+			if storageID == dummyUUID {
+				list.List[dummyUUID] = StorageBackupScheduleProperties{
+					ObjectUUID:         dummyUUID,
+					Name:               "daily",
+					Active:             true,
+					KeepBackups:        1,
+					NextRuntime:        dummyTime,
+					BackupLocationUUID: backupCenterUUID,
+				}
+				list.List[unprotectedUUID] = StorageBackupScheduleProperties{
+					ObjectUUID:  unprotectedUUID,
+					Name:        "inactive",
+					Active:      false,
+					NextRuntime: dummyTime,
+				}
+			}
+			res, _ := json.Marshal(list)
+			fmt.Fprint(w, string(res))
+		})
+		mux.HandleFunc(path.Join(apiStorageBase, storageID, "snapshot_schedules"), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+			list := StorageSnapshotScheduleList{List: map[string]StorageSnapshotScheduleProperties{}}
+			if storageID == dummyUUID {
+				list.List[dummyUUID] = StorageSnapshotScheduleProperties{
+					ObjectUUID:    dummyUUID,
+					Name:          "hourly",
+					KeepSnapshots: 2,
+					NextRuntime:   nextRuntime,
+				}
+			}
+			res, _ := json.Marshal(list)
+			fmt.Fprint(w, string(res))
+		})
+	}
+
+	report, err := client.AuditBackupCompliance(emptyCtx, BackupCompliancePolicy{
+		MinKeepBackups:        3,
+		MinKeepSnapshots:      5,
+		RequireOffsiteBackups: true,
+	})
+	assert.Nil(t, err, "AuditBackupCompliance returned an error %v", err)
+	assert.False(t, report.IsCompliant())
+	assert.Equal(t, 2, report.StorageCount)
+	var findings []string
+	for _, finding := range report.Findings {
+		findings = append(findings, fmt.Sprintf("%s %s %s", finding.StorageName, finding.Type, finding.ScheduleUUID))
+	}
+	assert.Equal(t, []string{
+		fmt.Sprintf("a %s %s", OverdueScheduleFinding, dummyUUID),
+		fmt.Sprintf("a %s %s", InsufficientRetentionFinding, dummyUUID),
+		fmt.Sprintf("a %s %s", SameLocationBackupFinding, dummyUUID),
+		fmt.Sprintf("a %s %s", InsufficientRetentionFinding, dummyUUID),
+		fmt.Sprintf("b %s ", NoActiveScheduleFinding),
+	}, findings)
+
+	report, err = client.AuditBackupCompliance(emptyCtx, BackupCompliancePolicy{
+		OverdueGracePeriod: time.Since(dummyTime.Time) + time.Hour,
+	})
+	assert.Nil(t, err, "AuditBackupCompliance returned an error %v", err)
+	assert.Equal(t, 1, len(report.Findings))
+	assert.Equal(t, NoActiveScheduleFinding, report.Findings[0].Type)
+}
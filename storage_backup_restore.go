@@ -0,0 +1,235 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StorageBackupRestoreRequest represents a request for restoring a storage to a point in time.
+type StorageBackupRestoreRequest struct {
+	// The point in time to restore. The newest backup created at or before this time is used.
+	PointInTime time.Time
+
+	// If true, the storage is rolled back in place. Servers the storage is attached to are stopped
+	// during the rollback and started again afterwards, if they were running.
+	// Otherwise, the backup is restored side-by-side as a new storage.
+	InPlace bool
+
+	// Name of the new storage of a side-by-side restore. Leave it empty to use the backup's name.
+	Name string
+
+	// UUID of a server the new storage of a side-by-side restore is attached to. Optional.
+	RecoveryServerUUID string
+}
+
+// StorageBackupRestoreResponse represents the result of restoring a storage.
+type StorageBackupRestoreResponse struct {
+	// The restored backup.
+	Backup StorageBackup
+
+	// The UUID of the restored storage, i.e. the storage itself for an in-place rollback,
+	// or the new storage for a side-by-side restore.
+	StorageUUID string
+}
+
+// StorageBackupCatalogueEntry represents a backup in the backup catalogue.
+type StorageBackupCatalogueEntry struct {
+	// The UUID of the backed up storage.
+	StorageUUID string
+
+	// The name of the backed up storage.
+	StorageName string
+
+	// The backup.
+	Backup StorageBackup
+
+	// The UUID of the backup schedule which took the backup. Empty if unknown.
+	BackupScheduleUUID string
+
+	// The UUID of the location where the backup is stored. Empty if unknown.
+	BackupLocationUUID string
+
+	// The name of the location where the backup is stored. Empty if unknown.
+	BackupLocationName string
+}
+
+// FindStorageBackupAt returns the newest backup created at or before a point in time.
+func FindStorageBackupAt(backups []StorageBackup, pointInTime time.Time) (StorageBackup, error) {
+	var found StorageBackup
+	isFound := false
+	for _, backup := range backups {
+		createTime := backup.Properties.CreateTime.Time
+		if createTime.After(pointInTime) {
+			continue
+		}
+		if !isFound || createTime.After(found.Properties.CreateTime.Time) {
+			found = backup
+			isFound = true
+		}
+	}
+	if !isFound {
+		return StorageBackup{}, fmt.Errorf("no backup found at or before %s", pointInTime.Format(time.RFC3339))
+	}
+	return found, nil
+}
+
+// RestoreStorageBackup restores a storage to a point in time using the newest backup created at or before
+// that time, either in place or side-by-side as a new storage.
+func (c *Client) RestoreStorageBackup(ctx context.Context, storageID string, body StorageBackupRestoreRequest) (StorageBackupRestoreResponse, error) {
+	if body.PointInTime.IsZero() {
+		return StorageBackupRestoreResponse{}, errors.New("'PointInTime' is empty")
+	}
+	if body.InPlace && body.RecoveryServerUUID != "" {
+		return StorageBackupRestoreResponse{}, errors.New("'RecoveryServerUUID' is only allowed for side-by-side restores")
+	}
+	if body.RecoveryServerUUID != "" && !isValidUUID(body.RecoveryServerUUID) {
+		return StorageBackupRestoreResponse{}, errors.New("'RecoveryServerUUID' is invalid")
+	}
+	backups, err := c.GetStorageBackupList(ctx, storageID)
+	if err != nil {
+		return StorageBackupRestoreResponse{}, err
+	}
+	backup, err := FindStorageBackupAt(backups, body.PointInTime)
+	if err != nil {
+		return StorageBackupRestoreResponse{}, err
+	}
+	response := StorageBackupRestoreResponse{Backup: backup}
+	if body.InPlace {
+		response.StorageUUID = storageID
+		return response, c.rollbackStorageBackupInPlace(ctx, storageID, backup.Properties.ObjectUUID)
+	}
+
+	name := body.Name
+	if name == "" {
+		name = backup.Properties.Name
+	}
+	created, err := c.CreateStorageFromBackup(ctx, backup.Properties.ObjectUUID, name)
+	if err != nil {
+		return StorageBackupRestoreResponse{}, err
+	}
+	response.StorageUUID = created.ObjectUUID
+	if body.RecoveryServerUUID != "" {
+		err = c.LinkStorage(ctx, body.RecoveryServerUUID, created.ObjectUUID, false)
+		if err != nil {
+			return response, fmt.Errorf("storage %s was restored, but attaching it to server %s failed: %v", created.ObjectUUID, body.RecoveryServerUUID, err)
+		}
+	}
+	return response, nil
+}
+
+// rollbackStorageBackupInPlace stops the running servers a storage is attached to, rolls the storage back
+// and starts the servers again.
+func (c *Client) rollbackStorageBackupInPlace(ctx context.Context, storageID, backupID string) error {
+	storage, err := c.GetStorage(ctx, storageID)
+	if err != nil {
+		return err
+	}
+	var stoppedServers []string
+	for _, relation := range storage.Properties.Relations.Servers {
+		isOn, err := c.IsServerOn(ctx, relation.ObjectUUID)
+		if err != nil {
+			return err
+		}
+		if !isOn {
+			continue
+		}
+		err = c.powerOffServer(ctx, relation.ObjectUUID)
+		if err != nil {
+			return joinErrors([]error{err, c.startServers(ctx, stoppedServers)})
+		}
+		stoppedServers = append(stoppedServers, relation.ObjectUUID)
+	}
+	err = c.RollbackStorageBackup(ctx, storageID, backupID, StorageRollbackRequest{Rollback: true})
+	return joinErrors([]error{err, c.startServers(ctx, stoppedServers)})
+}
+
+// powerOffServer shuts a server down gracefully, or powers it off if that fails, and waits until the server is off,
+// even if the client is not synchronous.
+func (c *Client) powerOffServer(ctx context.Context, id string) error {
+	err := c.ShutdownServer(ctx, id)
+	if err != nil {
+		err = c.StopServer(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	return c.waitForServerPowerStatus(ctx, id, false)
+}
+
+// startServers starts the given servers.
+func (c *Client) startServers(ctx context.Context, ids []string) error {
+	var errs []error
+	for _, id := range ids {
+		err := c.StartServer(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("starting server %s failed: %v", id, err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// GetStorageBackupCatalogue returns the backups of all storages, along with the schedules which took them
+// and the locations where they are stored. The entries are sorted by storage name and newest backup first.
+func (c *Client) GetStorageBackupCatalogue(ctx context.Context) ([]StorageBackupCatalogueEntry, error) {
+	storages, err := c.GetStorageList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := c.GetStorageBackupLocationList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locationNames := make(map[string]string)
+	for _, location := range locations {
+		locationNames[location.Properties.ObjectUUID] = location.Properties.Name
+	}
+	var catalogue []StorageBackupCatalogueEntry
+	for _, storage := range storages {
+		backups, err := c.GetStorageBackupList(ctx, storage.Properties.ObjectUUID)
+		if err != nil {
+			return nil, err
+		}
+		if len(backups) == 0 {
+			continue
+		}
+		schedules, err := c.GetStorageBackupScheduleList(ctx, storage.Properties.ObjectUUID)
+		if err != nil {
+			return nil, err
+		}
+		backupSchedules := make(map[string]StorageBackupScheduleProperties)
+		for _, schedule := range schedules {
+			for _, relation := range schedule.Properties.Relations.StorageBackups {
+				backupSchedules[relation.ObjectUUID] = schedule.Properties
+			}
+		}
+		for _, backup := range backups {
+			entry := StorageBackupCatalogueEntry{
+				StorageUUID: storage.Properties.ObjectUUID,
+				StorageName: storage.Properties.Name,
+				Backup:      backup,
+			}
+			if schedule, ok := backupSchedules[backup.Properties.ObjectUUID]; ok {
+				entry.BackupScheduleUUID = schedule.ObjectUUID
+				entry.BackupLocationUUID = schedule.BackupLocationUUID
+				entry.BackupLocationName = schedule.BackupLocationName
+				if entry.BackupLocationName == "" {
+					entry.BackupLocationName = locationNames[schedule.BackupLocationUUID]
+				}
+			}
+			catalogue = append(catalogue, entry)
+		}
+	}
+	sort.SliceStable(catalogue, func(i, j int) bool {
+		if catalogue[i].StorageName != catalogue[j].StorageName {
+			return catalogue[i].StorageName < catalogue[j].StorageName
+		}
+		if catalogue[i].StorageUUID != catalogue[j].StorageUUID {
+			return catalogue[i].StorageUUID < catalogue[j].StorageUUID
+		}
+		return catalogue[i].Backup.Properties.CreateTime.After(catalogue[j].Backup.Properties.CreateTime.Time)
+	})
+	return catalogue, nil
+}
@@ -0,0 +1,205 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockOldBackupUUID = "11111111-1111-4111-8111-111111111111"
+	mockNewBackupUUID = "22222222-2222-4222-8222-222222222222"
+)
+
+func TestFindStorageBackupAt(t *testing.T) {
+	backups := getMockRestoreStorageBackups()
+	testCases := []struct {
+		pointInTime    time.Time
+		expectedBackup string
+		isFailed       bool
+	}{
+		{pointInTime: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), expectedBackup: mockOldBackupUUID},
+		{pointInTime: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), expectedBackup: mockNewBackupUUID},
+		{pointInTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), expectedBackup: mockOldBackupUUID},
+		{pointInTime: time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), isFailed: true},
+	}
+	for _, test := range testCases {
+		backup, err := FindStorageBackupAt(backups, test.pointInTime)
+		if test.isFailed {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err, "FindStorageBackupAt returned an error %v", err)
+		assert.Equal(t, test.expectedBackup, backup.Properties.ObjectUUID)
+	}
+}
+
+func TestClient_RestoreStorageBackup(t *testing.T) {
+	// A server is powered off before rolling back, even if the client does not wait for the shutdown.
+	for _, isSynchronous := range []bool{true, false} {
+		testRestoreStorageBackup(t, isSynchronous)
+	}
+}
+
+func testRestoreStorageBackup(t *testing.T, isSynchronous bool) {
+	server, client, mux := setupTestClient(isSynchronous)
+	defer server.Close()
+	newStorageUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	power := true
+	// The server is still running for the first GET after a shutdown request.
+	var poweringOff bool
+	var events []string
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "backups"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareRestoreStorageBackupListHTTPGet())
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		storage := getMockStorage("active")
+		storage.Properties.Relations.Servers = []StorageServerRelation{{ObjectUUID: dummyUUID}}
+		res, _ := json.Marshal(storage)
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "backups", mockOldBackupUUID, "rollback"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		events = append(events, fmt.Sprintf("rollback (power %v)", power))
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareServerHTTPGet(power, "active"))
+		if poweringOff {
+			power, poweringOff = false, false
+		}
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID, "shutdown"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		poweringOff = true
+		events = append(events, "shutdown")
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID, "power"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		var body ServerPowerUpdateRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		power = body.Power
+		events = append(events, fmt.Sprintf("power %v", power))
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, "import"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		var body CreateStorageFromBackupRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		events = append(events, fmt.Sprintf("import %s as %s", body.RequestProperties.BackupUUID, body.RequestProperties.Name))
+		fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, newStorageUUID, dummyRequestUUID)
+	})
+	mux.HandleFunc(path.Join(apiServerBase, dummyUUID, "storages"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		var body ServerStorageRelationCreateRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		events = append(events, "link "+body.ObjectUUID)
+	})
+	pointInTime := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	response, err := client.RestoreStorageBackup(emptyCtx, dummyUUID, StorageBackupRestoreRequest{
+		PointInTime: pointInTime,
+		InPlace:     true,
+	})
+	assert.Nil(t, err, "RestoreStorageBackup returned an error %v", err)
+	assert.Equal(t, dummyUUID, response.StorageUUID)
+	assert.Equal(t, mockOldBackupUUID, response.Backup.Properties.ObjectUUID)
+	assert.Equal(t, []string{"shutdown", "rollback (power false)", "power true"}, events)
+
+	events = nil
+	response, err = client.RestoreStorageBackup(emptyCtx, dummyUUID, StorageBackupRestoreRequest{
+		PointInTime:        pointInTime,
+		Name:               "recovered",
+		RecoveryServerUUID: dummyUUID,
+	})
+	assert.Nil(t, err, "RestoreStorageBackup returned an error %v", err)
+	assert.Equal(t, newStorageUUID, response.StorageUUID)
+	assert.Equal(t, []string{"import " + mockOldBackupUUID + " as recovered", "link " + newStorageUUID}, events)
+
+	_, err = client.RestoreStorageBackup(emptyCtx, dummyUUID, StorageBackupRestoreRequest{})
+	assert.NotNil(t, err)
+	_, err = client.RestoreStorageBackup(emptyCtx, dummyUUID, StorageBackupRestoreRequest{
+		PointInTime:        pointInTime,
+		InPlace:            true,
+		RecoveryServerUUID: dummyUUID,
+	})
+	assert.NotNil(t, err)
+	_, err = client.RestoreStorageBackup(emptyCtx, dummyUUID, StorageBackupRestoreRequest{
+		PointInTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NotNil(t, err)
+}
+
+func TestClient_GetStorageBackupCatalogue(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	mux.HandleFunc(apiStorageBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareStorageListHTTPGet())
+	})
+	mux.HandleFunc(apiBackupLocationBase, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprintf(w, `{"backup_locations": {"%s": {"object_uuid": "%s", "name": "de/fra"}}}`, dummyUUID, dummyUUID)
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "backups"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareRestoreStorageBackupListHTTPGet())
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "backup_schedules"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		schedule := StorageBackupScheduleProperties{
+			ObjectUUID:         dummyUUID,
+			BackupLocationUUID: dummyUUID,
+			Relations: StorageBackupScheduleRelations{
+				StorageBackups: []StorageBackupScheduleRelation{{ObjectUUID: mockNewBackupUUID}},
+			},
+		}
+		res, _ := json.Marshal(StorageBackupScheduleList{List: map[string]StorageBackupScheduleProperties{dummyUUID: schedule}})
+		fmt.Fprint(w, string(res))
+	})
+	catalogue, err := client.GetStorageBackupCatalogue(emptyCtx)
+	assert.Nil(t, err, "GetStorageBackupCatalogue returned an error %v", err)
+	assert.Equal(t, 2, len(catalogue))
+	assert.Equal(t, mockNewBackupUUID, catalogue[0].Backup.Properties.ObjectUUID)
+	assert.Equal(t, dummyUUID, catalogue[0].BackupScheduleUUID)
+	assert.Equal(t, "de/fra", catalogue[0].BackupLocationName)
+	assert.Equal(t, "test", catalogue[0].StorageName)
+	assert.Equal(t, mockOldBackupUUID, catalogue[1].Backup.Properties.ObjectUUID)
+	assert.Equal(t, "", catalogue[1].BackupLocationUUID)
+}
+
+func getMockRestoreStorageBackups() []StorageBackup {
+	return []StorageBackup{
+		{Properties: StorageBackupProperties{
+			ObjectUUID: mockNewBackupUUID,
+			Name:       "new",
+			CreateTime: GSTime{time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)},
+		}},
+		{Properties: StorageBackupProperties{
+			ObjectUUID: mockOldBackupUUID,
+			Name:       "old",
+			CreateTime: GSTime{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		}},
+	}
+}
+
+func prepareRestoreStorageBackupListHTTPGet() string {
+	list := StorageBackupList{List: make(map[string]StorageBackupProperties)}
+	for _, backup := range getMockRestoreStorageBackups() {
+		list.List[backup.Properties.ObjectUUID] = backup.Properties
+	}
+	res, _ := json.Marshal(list)
+	return string(res)
+}
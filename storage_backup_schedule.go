@@ -14,6 +14,7 @@ type StorageBackupScheduleOperator interface {
 	CreateStorageBackupSchedule(ctx context.Context, id string, body StorageBackupScheduleCreateRequest)
 	UpdateStorageBackupSchedule(ctx context.Context, storageID, scheduleID string, body StorageBackupScheduleUpdateRequest) error
 	DeleteStorageBackupSchedule(ctx context.Context, storageID, scheduleID string) error
+	AuditBackupCompliance(ctx context.Context, policy BackupCompliancePolicy) (BackupComplianceReport, error)
 }
 
 // StorageBackupScheduleList contains a list of storage backup schedules.
@@ -0,0 +1,190 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Stages of a storage migration, reported to StorageMigrationOptions.Progress.
+const (
+	StorageMigrationStageSnapshotting     = "snapshotting"
+	StorageMigrationStageExporting        = "exporting"
+	StorageMigrationStageCreatingTemplate = "creating template"
+	StorageMigrationStageCreatingStorage  = "creating storage"
+	StorageMigrationStageCleaningUp       = "cleaning up"
+	StorageMigrationStageDone             = "done"
+)
+
+// StorageMigrationOptions represents the options of a storage migration.
+type StorageMigrationOptions struct {
+	// Client used to create the new storage. As the location of an object depends on the location of
+	// its project, it must be configured for a project in the target location, with access to the templates
+	// of the migrating client's project. Leave it nil to use the migrating client, which only works if
+	// the target location is the storage's location.
+	TargetClient *Client
+
+	// Name of the new storage. Leave it empty to use the name of the source storage.
+	Name string
+
+	// SSH keys, password and hostname of the new storage. The template UUID is set by the migration.
+	Template StorageTemplate
+
+	// Additional export of the intermediate snapshot to the object storage (see ExportStorageSnapshotToObjectStorage),
+	// e.g. as an off-site copy of the migrated disk. The exported object is not an intermediate: it is kept,
+	// and billed, until it is deleted from the bucket. Leave the bucket empty to skip the export.
+	Export SnapshotExportRequest
+
+	// Called when the migration enters a new stage (see StorageMigrationStage* constants). Optional.
+	Progress func(stage string)
+}
+
+// StorageMigrationResponse represents the result of a storage migration.
+type StorageMigrationResponse struct {
+	// The UUID of the new storage.
+	StorageUUID string
+
+	// The exported snapshot, if opts.Export has been set. The object is kept in the object storage.
+	Export SnapshotExportResponse
+}
+
+// MigrateStorage copies a storage to another location: it snapshots the storage, optionally exports the
+// snapshot to the object storage, creates a template from the snapshot, and creates a new storage from the
+// template in the target location. The intermediate snapshot and template are deleted afterwards, even if
+// the context is done. The source storage is left untouched.
+// If only the cleanup fails, the response is returned along with the error.
+func (c *Client) MigrateStorage(ctx context.Context, storageID, targetLocationUUID string, opts StorageMigrationOptions) (response StorageMigrationResponse, err error) {
+	if !isValidUUID(targetLocationUUID) {
+		return StorageMigrationResponse{}, errors.New("'targetLocationUUID' is invalid")
+	}
+	target := opts.TargetClient
+	if target == nil {
+		target = c
+	}
+	progress := func(stage string) {
+		if opts.Progress != nil {
+			opts.Progress(stage)
+		}
+	}
+	storage, err := c.GetStorage(ctx, storageID)
+	if err != nil {
+		return StorageMigrationResponse{}, err
+	}
+	// Check the target location before creating anything.
+	if opts.TargetClient == nil && storage.Properties.LocationUUID != targetLocationUUID {
+		return StorageMigrationResponse{}, fmt.Errorf("'TargetClient' is required, as storages are created in location %s of the project", storage.Properties.LocationUUID)
+	}
+	_, err = target.GetLocation(ctx, targetLocationUUID)
+	if err != nil {
+		return StorageMigrationResponse{}, fmt.Errorf("target location %s is not available: %v", targetLocationUUID, err)
+	}
+	name := opts.Name
+	if name == "" {
+		name = storage.Properties.Name
+	}
+	// Intermediate objects are cleaned up in reverse order of their creation.
+	var cleanups []func(ctx context.Context) error
+	defer func() {
+		if len(cleanups) > 0 {
+			progress(StorageMigrationStageCleaningUp)
+		}
+		// The cleanup has to run even if the given context is already done.
+		cleanupCtx := ctx
+		if cleanupCtx.Err() != nil {
+			cleanupCtx = context.Background()
+		}
+		var cleanupErrs []error
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanupErrs = append(cleanupErrs, cleanups[i](cleanupCtx))
+		}
+		cleanupErr := joinErrors(cleanupErrs)
+		if cleanupErr != nil {
+			if err == nil {
+				err = fmt.Errorf("cleanup failed: %v", cleanupErr)
+				return
+			}
+			err = fmt.Errorf("%v; cleanup failed: %v", err, cleanupErr)
+		}
+		if err == nil {
+			progress(StorageMigrationStageDone)
+		}
+	}()
+
+	progress(StorageMigrationStageSnapshotting)
+	snapshot, err := c.CreateStorageSnapshot(ctx, storageID, StorageSnapshotCreateRequest{
+		Name: fmt.Sprintf("migration of %s", storage.Properties.Name),
+	})
+	if err != nil {
+		return StorageMigrationResponse{}, err
+	}
+	cleanups = append(cleanups, func(ctx context.Context) error {
+		deleteErr := c.DeleteStorageSnapshot(ctx, storageID, snapshot.ObjectUUID)
+		if deleteErr != nil {
+			return fmt.Errorf("deleting snapshot %s failed: %v", snapshot.ObjectUUID, deleteErr)
+		}
+		return nil
+	})
+
+	if opts.Export.Bucket != "" {
+		progress(StorageMigrationStageExporting)
+		response.Export, err = c.ExportStorageSnapshotToObjectStorage(ctx, storageID, snapshot.ObjectUUID, opts.Export)
+		if err != nil {
+			return StorageMigrationResponse{}, err
+		}
+	}
+
+	progress(StorageMigrationStageCreatingTemplate)
+	template, err := c.CreateTemplate(ctx, TemplateCreateRequest{
+		Name:         fmt.Sprintf("migration of %s", storage.Properties.Name),
+		SnapshotUUID: snapshot.ObjectUUID,
+	})
+	if err != nil {
+		return StorageMigrationResponse{}, err
+	}
+	cleanups = append(cleanups, func(ctx context.Context) error {
+		deleteErr := c.DeleteTemplate(ctx, template.ObjectUUID)
+		if deleteErr != nil {
+			return fmt.Errorf("deleting template %s failed: %v", template.ObjectUUID, deleteErr)
+		}
+		return nil
+	})
+	_, err = waitForResource(ctx, c.DelayInterval(), func() (Template, error) {
+		return c.GetTemplate(ctx, template.ObjectUUID)
+	}, func(template Template) bool {
+		return template.Properties.Status == resourceActiveStatus
+	})
+	if err != nil {
+		return StorageMigrationResponse{}, fmt.Errorf("creating template %s failed: %v", template.ObjectUUID, err)
+	}
+
+	progress(StorageMigrationStageCreatingStorage)
+	storageTemplate := opts.Template
+	storageTemplate.TemplateUUID = template.ObjectUUID
+	created, err := target.CreateStorage(ctx, StorageCreateRequest{
+		Capacity:       storage.Properties.Capacity,
+		Name:           name,
+		StorageType:    StorageType(storage.Properties.StorageType),
+		StorageVariant: StorageVariant(storage.Properties.StorageVariant),
+		Template:       &storageTemplate,
+		Labels:         storage.Properties.Labels,
+	})
+	if err != nil {
+		return StorageMigrationResponse{}, err
+	}
+	newStorage, err := target.GetStorage(ctx, created.ObjectUUID)
+	if err == nil && newStorage.Properties.LocationUUID != targetLocationUUID {
+		err = fmt.Errorf("storage %s was created in location %s instead of %s", created.ObjectUUID, newStorage.Properties.LocationUUID, targetLocationUUID)
+	}
+	if err != nil {
+		cleanups = append(cleanups, func(ctx context.Context) error {
+			deleteErr := target.DeleteStorage(ctx, created.ObjectUUID)
+			if deleteErr != nil {
+				return fmt.Errorf("deleting storage %s failed: %v", created.ObjectUUID, deleteErr)
+			}
+			return nil
+		})
+		return StorageMigrationResponse{}, err
+	}
+	response.StorageUUID = created.ObjectUUID
+	return response, nil
+}
@@ -0,0 +1,202 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_MigrateStorage(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	targetServer, targetClient, targetMux := setupTestClient(true)
+	defer targetServer.Close()
+	targetLocationUUID := "5b6c7d8e-9f0a-4b1c-8d2e-3f4a5b6c7d8e"
+	newStorageUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	snapshotUUID := "11111111-1111-4111-8111-111111111111"
+	templateUUID := "22222222-2222-4222-8222-222222222222"
+	var createdStorage StorageCreateRequest
+	var createdTemplate TemplateCreateRequest
+	var newStorageLocationUUID string
+	var snapshots int
+	var deleted []string
+	var exports int
+	var cancelTemplate context.CancelFunc
+
+	// Source project.
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		fmt.Fprint(w, prepareStorageHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "snapshots"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		snapshots++
+		fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, snapshotUUID, dummyRequestUUID)
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "snapshots", snapshotUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, "snapshot")
+			return
+		}
+		fmt.Fprint(w, prepareStorageSnapshotHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "snapshots", snapshotUUID, "export_to_s3"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		exports++
+	})
+	mux.HandleFunc(apiTemplateBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		json.NewDecoder(r.Body).Decode(&createdTemplate)
+		fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, templateUUID, dummyRequestUUID)
+	})
+	mux.HandleFunc(path.Join(apiTemplateBase, templateUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, "template")
+			return
+		}
+		status := "active"
+		if cancelTemplate != nil {
+			// The migration is cancelled while the template is created.
+			status = "in-provisioning"
+			cancelTemplate()
+		}
+		res, _ := json.Marshal(getMockTemplate(status))
+		fmt.Fprint(w, string(res))
+	})
+	mux.HandleFunc(path.Join(apiLocationBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(getMockLocation())
+		fmt.Fprint(w, string(res))
+	})
+
+	// Target project.
+	targetMux.HandleFunc(path.Join(apiLocationBase, targetLocationUUID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(getMockLocation())
+		fmt.Fprint(w, string(res))
+	})
+	targetMux.HandleFunc(apiStorageBase, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		json.NewDecoder(r.Body).Decode(&createdStorage)
+		fmt.Fprintf(w, `{"object_uuid": "%s", "request_uuid": "%s"}`, newStorageUUID, dummyRequestUUID)
+	})
+	targetMux.HandleFunc(path.Join(apiStorageBase, newStorageUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, "storage")
+			return
+		}
+		storage := getMockStorage("active")
+		storage.Properties.ObjectUUID = newStorageUUID
+		storage.Properties.LocationUUID = newStorageLocationUUID
+		res, _ := json.Marshal(storage)
+		fmt.Fprint(w, string(res))
+	})
+
+	export := SnapshotExportRequest{Bucket: "migrations", AccessKey: "access", SecretKey: "secret"}
+	testCases := []struct {
+		newStorageLocationUUID string
+		isExported             bool
+		isCancelled            bool
+		isFailed               bool
+		expectedDeleted        []string
+	}{
+		{
+			newStorageLocationUUID: targetLocationUUID,
+			isExported:             true,
+			expectedDeleted:        []string{"template", "snapshot"},
+		},
+		{
+			newStorageLocationUUID: targetLocationUUID,
+			expectedDeleted:        []string{"template", "snapshot"},
+		},
+		{
+			newStorageLocationUUID: dummyUUID,
+			isFailed:               true,
+			expectedDeleted:        []string{"storage", "template", "snapshot"},
+		},
+		{
+			isCancelled:     true,
+			isFailed:        true,
+			expectedDeleted: []string{"template", "snapshot"},
+		},
+	}
+	for _, test := range testCases {
+		newStorageLocationUUID = test.newStorageLocationUUID
+		deleted = nil
+		ctx, cancel := context.WithCancel(emptyCtx)
+		cancelTemplate = nil
+		if test.isCancelled {
+			cancelTemplate = cancel
+		}
+		exports = 0
+		opts := StorageMigrationOptions{
+			TargetClient: targetClient,
+			Template:     StorageTemplate{Sshkeys: []string{dummyUUID}},
+		}
+		if test.isExported {
+			opts.Export = export
+		}
+		var stages []string
+		opts.Progress = func(stage string) {
+			stages = append(stages, stage)
+		}
+		response, err := client.MigrateStorage(ctx, dummyUUID, targetLocationUUID, opts)
+		cancel()
+		assert.Equal(t, test.expectedDeleted, deleted)
+		if test.isFailed {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err, "MigrateStorage returned an error %v", err)
+		assert.Equal(t, newStorageUUID, response.StorageUUID)
+		assert.Equal(t, snapshotUUID, createdTemplate.SnapshotUUID)
+		assert.Equal(t, StorageCreateRequest{
+			Capacity:    10,
+			Name:        "test",
+			StorageType: DefaultStorageType,
+			Template:    &StorageTemplate{Sshkeys: []string{dummyUUID}, TemplateUUID: templateUUID},
+			Labels:      getMockStorage("active").Properties.Labels,
+		}, createdStorage)
+		expectedStages := []string{StorageMigrationStageSnapshotting}
+		if test.isExported {
+			assert.Equal(t, 1, exports)
+			assert.Equal(t, "migrations", response.Export.Bucket)
+			expectedStages = append(expectedStages, StorageMigrationStageExporting)
+		} else {
+			assert.Equal(t, 0, exports)
+			assert.Equal(t, SnapshotExportResponse{}, response.Export)
+		}
+		expectedStages = append(expectedStages,
+			StorageMigrationStageCreatingTemplate,
+			StorageMigrationStageCreatingStorage,
+			StorageMigrationStageCleaningUp,
+			StorageMigrationStageDone,
+		)
+		assert.Equal(t, expectedStages, stages)
+	}
+
+	// Nothing is created if the target location cannot be reached.
+	snapshots = 0
+	_, err := client.MigrateStorage(emptyCtx, dummyUUID, targetLocationUUID, StorageMigrationOptions{Export: export})
+	assert.NotNil(t, err)
+	_, err = client.MigrateStorage(emptyCtx, dummyUUID, dummyUUID, StorageMigrationOptions{TargetClient: targetClient, Export: export})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, snapshots)
+
+	_, err = client.MigrateStorage(emptyCtx, dummyUUID, "", StorageMigrationOptions{Export: export})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,157 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// Capacity limits of a storage in GB.
+const (
+	minStorageCapacity = 1
+	maxStorageCapacity = 4096
+)
+
+// storageTypeRanks orders the storage types by performance, a storage can only be upgraded to a higher rank.
+var storageTypeRanks = map[StorageType]int{
+	DefaultStorageType: 0,
+	HighStorageType:    1,
+	InsaneStorageType:  2,
+}
+
+// GuestCommandRunner runs commands inside the guest of a server, e.g. via SSH.
+type GuestCommandRunner interface {
+	// RunCommand runs a command and returns its output.
+	RunCommand(ctx context.Context, command string) (string, error)
+}
+
+// StorageResizeHook is called after a storage was resized, e.g. to grow the guest's file system.
+type StorageResizeHook func(ctx context.Context, storage Storage) error
+
+// StorageResizeRequest represents a request for resizing a storage.
+type StorageResizeRequest struct {
+	// The new capacity of the storage in GB. It must be larger than the current capacity.
+	// Leave it 0 to keep the capacity.
+	Capacity int
+
+	// The new storage type. Only upgrades are supported. Leave it empty to keep the storage type.
+	StorageType StorageType
+
+	// If true, a snapshot of the storage is taken before resizing it.
+	SafetySnapshot bool
+
+	// Called after the storage is resized and active again. Optional.
+	GuestHook StorageResizeHook
+}
+
+// StorageResizeResponse represents the result of resizing a storage.
+type StorageResizeResponse struct {
+	// The UUID of the safety snapshot, if it was requested.
+	SnapshotUUID string
+}
+
+// ResizeStorage grows a storage and/or upgrades its storage type, waits for the storage to become active,
+// and calls the guest hook to grow the file system. The safety snapshot is not deleted afterwards.
+func (c *Client) ResizeStorage(ctx context.Context, id string, body StorageResizeRequest) (StorageResizeResponse, error) {
+	if body.Capacity == 0 && body.StorageType == "" {
+		return StorageResizeResponse{}, errors.New("'Capacity' or 'StorageType' is required")
+	}
+	if body.Capacity != 0 && (body.Capacity < minStorageCapacity || body.Capacity > maxStorageCapacity) {
+		return StorageResizeResponse{}, fmt.Errorf("'Capacity' must be between %d and %d GB", minStorageCapacity, maxStorageCapacity)
+	}
+	storage, err := c.GetStorage(ctx, id)
+	if err != nil {
+		return StorageResizeResponse{}, err
+	}
+	if body.Capacity != 0 && body.Capacity <= storage.Properties.Capacity {
+		return StorageResizeResponse{}, fmt.Errorf("'Capacity' must be larger than the current capacity of %d GB, downsizing is not supported", storage.Properties.Capacity)
+	}
+	if body.StorageType != "" {
+		err = validateStorageTypeUpgrade(StorageType(storage.Properties.StorageType), body.StorageType)
+		if err != nil {
+			return StorageResizeResponse{}, err
+		}
+	}
+
+	var response StorageResizeResponse
+	if body.SafetySnapshot {
+		snapshot, err := c.CreateStorageSnapshot(ctx, id, StorageSnapshotCreateRequest{
+			Name: fmt.Sprintf("before resizing %s", storage.Properties.Name),
+		})
+		if err != nil {
+			return StorageResizeResponse{}, fmt.Errorf("safety snapshot failed: %v", err)
+		}
+		response.SnapshotUUID = snapshot.ObjectUUID
+	}
+	err = c.UpdateStorage(ctx, id, StorageUpdateRequest{
+		Capacity:    body.Capacity,
+		StorageType: body.StorageType,
+	})
+	if err != nil {
+		return response, err
+	}
+	storage, err = c.waitForStorageActive(ctx, id)
+	if err != nil {
+		return response, err
+	}
+	if body.GuestHook != nil {
+		err = body.GuestHook(ctx, storage)
+		if err != nil {
+			return response, fmt.Errorf("guest hook failed: %v", err)
+		}
+	}
+	return response, nil
+}
+
+// NewGuestCommandHook returns a storage resize hook which runs the given commands in the guest one by one.
+func NewGuestCommandHook(runner GuestCommandRunner, commands ...string) StorageResizeHook {
+	return func(ctx context.Context, storage Storage) error {
+		for _, command := range commands {
+			output, err := runner.RunCommand(ctx, command)
+			if err != nil {
+				return fmt.Errorf("command %q failed: %v: %s", command, err, output)
+			}
+		}
+		return nil
+	}
+}
+
+// GrowFilesystemCommands returns the commands growing a partition of a device and its ext2/3/4 file system,
+// e.g. "growpart /dev/sda 1" and "resize2fs /dev/sda1". As by the kernel's naming, a "p" separates the partition
+// from a device name ending in a digit, e.g. "resize2fs /dev/nvme0n1p1".
+func GrowFilesystemCommands(device string, partition int) []string {
+	partitionDevice := fmt.Sprintf("%s%d", device, partition)
+	if device != "" && unicode.IsDigit(rune(device[len(device)-1])) {
+		partitionDevice = fmt.Sprintf("%sp%d", device, partition)
+	}
+	return []string{
+		fmt.Sprintf("growpart %s %d", device, partition),
+		"resize2fs " + partitionDevice,
+	}
+}
+
+// validateStorageTypeUpgrade checks if a storage type can be changed to another one.
+func validateStorageTypeUpgrade(from, to StorageType) error {
+	fromRank, ok := storageTypeRanks[from]
+	if !ok {
+		return fmt.Errorf("current storage type %s is unknown", from)
+	}
+	toRank, ok := storageTypeRanks[to]
+	if !ok {
+		return fmt.Errorf("storage type %s is unknown", to)
+	}
+	if toRank < fromRank {
+		return fmt.Errorf("downgrading storage type %s to %s is not supported", from, to)
+	}
+	return nil
+}
+
+// waitForStorageActive waits until a storage is active.
+func (c *Client) waitForStorageActive(ctx context.Context, id string) (Storage, error) {
+	return waitForResource(ctx, c.DelayInterval(), func() (Storage, error) {
+		return c.GetStorage(ctx, id)
+	}, func(storage Storage) bool {
+		return storage.Properties.Status == resourceActiveStatus
+	})
+}
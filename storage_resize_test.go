@@ -0,0 +1,116 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockGuestCommandRunner struct {
+	commands []string
+	isFailed bool
+}
+
+func (r *mockGuestCommandRunner) RunCommand(ctx context.Context, command string) (string, error) {
+	r.commands = append(r.commands, command)
+	if r.isFailed {
+		return "error output", errors.New("just test")
+	}
+	return "", nil
+}
+
+func TestClient_ResizeStorage(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var updates []StorageUpdateRequest
+	var isSnapshotTaken bool
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if r.Method == http.MethodPatch {
+			var body StorageUpdateRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			updates = append(updates, body)
+			return
+		}
+		fmt.Fprint(w, prepareStorageHTTPGet("active"))
+	})
+	mux.HandleFunc(path.Join(apiStorageBase, dummyUUID, "snapshots"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		isSnapshotTaken = true
+		fmt.Fprint(w, prepareStorageSnapshotCreateResponseHTTP())
+	})
+	testCases := []struct {
+		request          StorageResizeRequest
+		isFailed         bool
+		expectedSnapshot string
+	}{
+		{
+			request:          StorageResizeRequest{Capacity: 20, StorageType: HighStorageType, SafetySnapshot: true},
+			expectedSnapshot: dummyUUID,
+		},
+		{
+			request: StorageResizeRequest{StorageType: InsaneStorageType},
+		},
+		{
+			request:  StorageResizeRequest{},
+			isFailed: true,
+		},
+		{
+			request:  StorageResizeRequest{Capacity: 5},
+			isFailed: true,
+		},
+		{
+			request:  StorageResizeRequest{Capacity: 5000},
+			isFailed: true,
+		},
+		{
+			request:  StorageResizeRequest{Capacity: 20, StorageType: "storage_unknown"},
+			isFailed: true,
+		},
+	}
+	for _, test := range testCases {
+		updates = nil
+		isSnapshotTaken = false
+		runner := &mockGuestCommandRunner{}
+		test.request.GuestHook = NewGuestCommandHook(runner, GrowFilesystemCommands("/dev/sda", 1)...)
+		response, err := client.ResizeStorage(emptyCtx, dummyUUID, test.request)
+		if test.isFailed {
+			assert.NotNil(t, err)
+			assert.Nil(t, updates)
+			assert.Nil(t, runner.commands)
+			continue
+		}
+		assert.Nil(t, err, "ResizeStorage returned an error %v", err)
+		assert.Equal(t, test.expectedSnapshot, response.SnapshotUUID)
+		assert.Equal(t, test.request.SafetySnapshot, isSnapshotTaken)
+		assert.Equal(t, []StorageUpdateRequest{{Capacity: test.request.Capacity, StorageType: test.request.StorageType}}, updates)
+		assert.Equal(t, []string{"growpart /dev/sda 1", "resize2fs /dev/sda1"}, runner.commands)
+	}
+
+	runner := &mockGuestCommandRunner{isFailed: true}
+	_, err := client.ResizeStorage(emptyCtx, dummyUUID, StorageResizeRequest{
+		Capacity:  20,
+		GuestHook: NewGuestCommandHook(runner, "resize2fs /dev/sda1"),
+	})
+	assert.NotNil(t, err)
+}
+
+func TestGrowFilesystemCommands(t *testing.T) {
+	assert.Equal(t, []string{"growpart /dev/sda 1", "resize2fs /dev/sda1"}, GrowFilesystemCommands("/dev/sda", 1))
+	assert.Equal(t, []string{"growpart /dev/nvme0n1 1", "resize2fs /dev/nvme0n1p1"}, GrowFilesystemCommands("/dev/nvme0n1", 1))
+	assert.Equal(t, []string{"growpart /dev/mmcblk0 2", "resize2fs /dev/mmcblk0p2"}, GrowFilesystemCommands("/dev/mmcblk0", 2))
+}
+
+func Test_validateStorageTypeUpgrade(t *testing.T) {
+	assert.Nil(t, validateStorageTypeUpgrade(DefaultStorageType, HighStorageType))
+	assert.Nil(t, validateStorageTypeUpgrade(HighStorageType, HighStorageType))
+	assert.NotNil(t, validateStorageTypeUpgrade(InsaneStorageType, DefaultStorageType))
+	assert.NotNil(t, validateStorageTypeUpgrade("unknown", HighStorageType))
+}
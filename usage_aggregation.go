@@ -0,0 +1,220 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UsageAggregationOptions represents the options of a usage aggregation.
+type UsageAggregationOptions struct {
+	// Key of the labels to group by, e.g. "team" for labels like "team=shop" or "team:shop".
+	// Leave it empty to group by the full labels.
+	LabelKey string
+
+	// If true, the usage of deleted resources is included.
+	IncludeDeleted bool
+
+	// If set, the cost of each row is calculated. Optional.
+	PriceList *PriceList
+}
+
+// UsagePivotRow represents a row of a usage pivot table.
+type UsagePivotRow struct {
+	// Value of the label key, or the full label if no label key is given. Empty for resources without matching label.
+	Label string `json:"label"`
+
+	// The UUID of the project. Empty for resource types without project.
+	ProjectUUID string `json:"project_uuid"`
+
+	// Start of the interval.
+	IntervalStart GSTime `json:"interval_start"`
+
+	// End of the interval.
+	IntervalEnd GSTime `json:"interval_end"`
+
+	// Usage per product number.
+	Usage map[int]float64 `json:"usage"`
+
+	// Cost of the usage, if a price list is given.
+	Cost float64 `json:"cost"`
+}
+
+// UsagePivotTable represents usage grouped by label, project and interval.
+type UsagePivotTable struct {
+	// Key of the grouped labels.
+	LabelKey string `json:"label_key"`
+
+	// Currency of the costs. Empty if no price list is given.
+	Currency string `json:"currency,omitempty"`
+
+	// Product numbers occurring in the rows, sorted ascending.
+	ProductNumbers []int `json:"product_numbers"`
+
+	// The rows, sorted by label, project and interval start.
+	Rows []UsagePivotRow `json:"rows"`
+
+	// True if the rows have costs.
+	hasCost bool
+}
+
+// resourcesUsageResult is the set of usage types which list single resources.
+type resourcesUsageResult interface {
+	ServersUsage | DistributedStoragesUsage | RocketStoragesUsage | StorageBackupsUsage | SnapshotsUsage |
+		TemplatesUsage | ISOImagesUsage | IPsUsage | LoadBalancersUsage | PaaSServicesUsage
+	Resources() []ResourceUsage
+}
+
+// GetAllResourcesUsage returns the usage of resources of all types, e.g. for AggregateUsageByLabel.
+func (c *Client) GetAllResourcesUsage(ctx context.Context, query UsageQuery) ([]ResourceUsage, error) {
+	getters := []func(context.Context, *Client, UsageQuery) ([]ResourceUsage, error){
+		getResourcesUsage[ServersUsage],
+		getResourcesUsage[DistributedStoragesUsage],
+		getResourcesUsage[RocketStoragesUsage],
+		getResourcesUsage[StorageBackupsUsage],
+		getResourcesUsage[SnapshotsUsage],
+		getResourcesUsage[TemplatesUsage],
+		getResourcesUsage[ISOImagesUsage],
+		getResourcesUsage[IPsUsage],
+		getResourcesUsage[LoadBalancersUsage],
+		getResourcesUsage[PaaSServicesUsage],
+	}
+	var resources []ResourceUsage
+	for _, getter := range getters {
+		typeResources, err := getter(ctx, c, query)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, typeResources...)
+	}
+	return resources, nil
+}
+
+// getResourcesUsage returns the usage of the resources of a type as ResourceUsage.
+func getResourcesUsage[T resourcesUsageResult](ctx context.Context, c *Client, query UsageQuery) ([]ResourceUsage, error) {
+	usage, err := GetUsage[T](ctx, c, query)
+	if err != nil {
+		return nil, err
+	}
+	return usage.Resources(), nil
+}
+
+// AggregateUsageByLabel groups the usage of resources by label, project and interval. The usage of a resource
+// with several matching labels is split evenly between them, so that the rows add up to the total usage.
+func AggregateUsageByLabel(resources []ResourceUsage, opts UsageAggregationOptions) UsagePivotTable {
+	table := UsagePivotTable{LabelKey: opts.LabelKey}
+	if opts.PriceList != nil {
+		table.Currency = opts.PriceList.Currency
+		table.hasCost = true
+	}
+	type rowKey struct {
+		label         string
+		projectUUID   string
+		intervalStart int64
+	}
+	rows := make(map[rowKey]*UsagePivotRow)
+	productNumbers := make(map[int]bool)
+	for _, resource := range resources {
+		if resource.Deleted && !opts.IncludeDeleted {
+			continue
+		}
+		labels := labelValues(resource.Labels, opts.LabelKey)
+		if len(labels) == 0 {
+			labels = []string{""}
+		}
+		share := 1 / float64(len(labels))
+		for _, interval := range resource.UsagePerInterval {
+			var cost float64
+			if opts.PriceList != nil {
+				cost, _ = opts.PriceList.UsageCost(interval.AccumulatedUsage)
+			}
+			for _, label := range labels {
+				key := rowKey{label, resource.ProjectUUID, interval.IntervalStart.UnixNano()}
+				row, ok := rows[key]
+				if !ok {
+					row = &UsagePivotRow{
+						Label:         label,
+						ProjectUUID:   resource.ProjectUUID,
+						IntervalStart: interval.IntervalStart,
+						IntervalEnd:   interval.IntervalEnd,
+						Usage:         make(map[int]float64),
+					}
+					rows[key] = row
+				}
+				if interval.IntervalEnd.After(row.IntervalEnd.Time) {
+					row.IntervalEnd = interval.IntervalEnd
+				}
+				for _, usage := range interval.AccumulatedUsage {
+					row.Usage[usage.ProductNumber] += float64(usage.Value) * share
+					productNumbers[usage.ProductNumber] = true
+				}
+				row.Cost += cost * share
+			}
+		}
+	}
+	for _, row := range rows {
+		table.Rows = append(table.Rows, *row)
+	}
+	sort.Slice(table.Rows, func(i, j int) bool {
+		a, b := table.Rows[i], table.Rows[j]
+		if a.Label != b.Label {
+			return a.Label < b.Label
+		}
+		if a.ProjectUUID != b.ProjectUUID {
+			return a.ProjectUUID < b.ProjectUUID
+		}
+		return a.IntervalStart.Before(b.IntervalStart.Time)
+	})
+	for productNumber := range productNumbers {
+		table.ProductNumbers = append(table.ProductNumbers, productNumber)
+	}
+	sort.Ints(table.ProductNumbers)
+	return table
+}
+
+// WriteCSV writes the pivot table as CSV with a header row. There is a column per product number,
+// and a cost column if a price list was given.
+func (t UsagePivotTable) WriteCSV(w io.Writer) error {
+	labelColumn := t.LabelKey
+	if labelColumn == "" {
+		labelColumn = "label"
+	}
+	header := []string{labelColumn, "project_uuid", "interval_start", "interval_end"}
+	for _, productNumber := range t.ProductNumbers {
+		header = append(header, "product_"+strconv.Itoa(productNumber))
+	}
+	if t.hasCost {
+		header = append(header, strings.TrimSuffix("cost_"+t.Currency, "_"))
+	}
+	writer := csv.NewWriter(w)
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record := []string{row.Label, row.ProjectUUID, row.IntervalStart.String(), row.IntervalEnd.String()}
+		for _, productNumber := range t.ProductNumbers {
+			record = append(record, strconv.FormatFloat(row.Usage[productNumber], 'f', -1, 64))
+		}
+		if t.hasCost {
+			record = append(record, strconv.FormatFloat(row.Cost, 'f', -1, 64))
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the pivot table as JSON.
+func (t UsagePivotTable) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t)
+}
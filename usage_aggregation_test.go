@@ -0,0 +1,127 @@
+package gsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateUsageByLabel(t *testing.T) {
+	day1 := GSTime{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	day2 := GSTime{time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}
+	resources := []ResourceUsage{
+		{
+			ResourceType: ServerUsageResource,
+			Name:         "web",
+			Labels:       []string{"team=shop", "env=prod"},
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 10}, {ProductNumber: 2, Value: 4}}},
+			},
+		},
+		{
+			ResourceType: SnapshotUsageResource,
+			Name:         "db backup",
+			Labels:       []string{"team:shop", "team:data"},
+			ProjectUUID:  dummyUUID,
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 3, Value: 6}}},
+			},
+		},
+		{
+			ResourceType: ServerUsageResource,
+			Name:         "old",
+			Labels:       []string{"team=shop"},
+			Deleted:      true,
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 2}}},
+			},
+		},
+		{
+			ResourceType: LoadBalancerUsageResource,
+			Name:         "lb",
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 2, Value: 1}}},
+			},
+		},
+	}
+	table := AggregateUsageByLabel(resources, UsageAggregationOptions{LabelKey: "team"})
+	assert.Equal(t, []int{1, 2, 3}, table.ProductNumbers)
+	assert.Equal(t, []UsagePivotRow{
+		{IntervalStart: day1, IntervalEnd: day2, Usage: map[int]float64{2: 1}},
+		{Label: "data", ProjectUUID: dummyUUID, IntervalStart: day1, IntervalEnd: day2, Usage: map[int]float64{3: 3}},
+		{Label: "shop", IntervalStart: day1, IntervalEnd: day2, Usage: map[int]float64{1: 10, 2: 4}},
+		{Label: "shop", ProjectUUID: dummyUUID, IntervalStart: day1, IntervalEnd: day2, Usage: map[int]float64{3: 3}},
+	}, table.Rows)
+
+	priceList, err := NewPriceList("EUR", ProductPrice{ProductNumber: 1, UnitPrice: 0.5})
+	assert.Nil(t, err, "NewPriceList returned an error %v", err)
+	table = AggregateUsageByLabel(resources, UsageAggregationOptions{LabelKey: "team", IncludeDeleted: true, PriceList: &priceList})
+	assert.Equal(t, map[int]float64{1: 12, 2: 4}, table.Rows[2].Usage)
+	assert.Equal(t, 6.0, table.Rows[2].Cost)
+
+	var csv bytes.Buffer
+	assert.Nil(t, table.WriteCSV(&csv))
+	expected := strings.Join([]string{
+		"team,project_uuid,interval_start,interval_end,product_1,product_2,product_3,cost_EUR",
+		fmt.Sprintf(",,%s,%s,0,1,0,0", day1, day2),
+		fmt.Sprintf("data,%s,%s,%s,0,0,3,0", dummyUUID, day1, day2),
+		fmt.Sprintf("shop,,%s,%s,12,4,0,6", day1, day2),
+		fmt.Sprintf("shop,%s,%s,%s,0,0,3,0", dummyUUID, day1, day2),
+	}, "\n") + "\n"
+	assert.Equal(t, expected, csv.String())
+
+	var output bytes.Buffer
+	assert.Nil(t, table.WriteJSON(&output))
+	var decoded UsagePivotTable
+	assert.Nil(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, "EUR", decoded.Currency)
+	assert.Equal(t, table.Rows, decoded.Rows)
+
+	table = AggregateUsageByLabel(resources[:1], UsageAggregationOptions{})
+	assert.Equal(t, []string{"env=prod", "team=shop"}, []string{table.Rows[0].Label, table.Rows[1].Label})
+	assert.Equal(t, 5.0, table.Rows[0].Usage[1])
+}
+
+func TestClient_GetAllResourcesUsage(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	var requested []string
+	mux.HandleFunc(apiProjectLevelUsage+"/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		resourceType := strings.TrimPrefix(r.URL.Path, apiProjectLevelUsage+"/")
+		requested = append(requested, resourceType)
+		fmt.Fprintf(w, `{"%s": [{"object_uuid": "%s", "name": "%s", "labels": ["team=shop"], "deleted": true, "project_uuid": "%s"}]}`,
+			resourceType, dummyUUID, resourceType, dummyUUID)
+	})
+	resources, err := client.GetAllResourcesUsage(emptyCtx, UsageQuery{
+		Level: ProjectLevelUsage,
+		From:  time.Now().Add(-time.Hour),
+	})
+	assert.Nil(t, err, "GetAllResourcesUsage returned an error %v", err)
+	assert.Equal(t, []string{
+		ServerUsageResource, DistributedStorageUsageResource, RocketStorageUsageResource, StorageBackupUsageResource,
+		SnapshotUsageResource, TemplateUsageResource, ISOImageUsageResource, IPUsageResource, LoadBalancerUsageResource,
+		PaaSServiceUsageResource,
+	}, requested)
+	assert.Equal(t, 10, len(resources))
+	for _, resource := range resources {
+		assert.Equal(t, resource.ResourceType, resource.Name)
+	}
+	assert.Equal(t, ResourceUsage{
+		ResourceType: SnapshotUsageResource,
+		ObjectUUID:   dummyUUID,
+		Name:         SnapshotUsageResource,
+		Labels:       []string{"team=shop"},
+		ProjectUUID:  dummyUUID,
+		Deleted:      true,
+	}, resources[4])
+	assert.Nil(t, resources[3].Labels)
+	assert.False(t, resources[8].Deleted)
+}
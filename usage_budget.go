@@ -0,0 +1,389 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Default values of UsageMonitor.
+const (
+	defaultUsageSpikeFactor = 2
+	defaultUsageTrendDays   = 7
+)
+
+// BudgetScope is the scope of the resources covered by a UsageBudget.
+type BudgetScope string
+
+// All available budget scopes.
+const (
+	// ProjectBudgetScope covers the resources of a project. The usage API only reports the project UUID
+	// of snapshots, templates, ISO images, IPs and PaaS services. Servers, storages, storage backups and
+	// load balancers are never covered, use a LabelBudgetScope or ResourceTypeBudgetScope budget for them.
+	ProjectBudgetScope BudgetScope = "project"
+
+	// LabelBudgetScope covers the resources with a label.
+	LabelBudgetScope BudgetScope = "label"
+
+	// ResourceTypeBudgetScope covers the resources of a type.
+	ResourceTypeBudgetScope BudgetScope = "resource_type"
+)
+
+// UsageAlertKind is the kind of a UsageAlert.
+type UsageAlertKind string
+
+// All available usage alert kinds.
+const (
+	// BudgetExceededAlert is raised if the cost of the current month exceeds a budget.
+	BudgetExceededAlert UsageAlertKind = "budget_exceeded"
+
+	// BudgetForecastAlert is raised if the forecast cost of the current month exceeds a budget.
+	BudgetForecastAlert UsageAlertKind = "budget_forecast"
+
+	// UsageSpikeAlert is raised if the daily cost of a resource rises by more than the spike factor.
+	UsageSpikeAlert UsageAlertKind = "usage_spike"
+
+	// NewProductAlert is raised if a product is used for the first time in the current month.
+	NewProductAlert UsageAlertKind = "new_product"
+)
+
+// UsageBudget represents a monthly budget.
+type UsageBudget struct {
+	// Name of the budget, used in alerts.
+	Name string
+
+	// Scope of the budget.
+	Scope BudgetScope
+
+	// The project UUID, the label (e.g. "team=shop") or the resource type (e.g. RocketStorageUsageResource),
+	// depending on the scope.
+	Value string
+
+	// Maximum cost per month, in the currency of the price list.
+	Limit float64
+}
+
+// UsageNotifier sends usage alerts, e.g. via mail or chat.
+type UsageNotifier interface {
+	// NotifyUsageAlerts sends the alerts of a usage check. It is only called if there are alerts.
+	NotifyUsageAlerts(ctx context.Context, alerts []UsageAlert) error
+}
+
+// UsageNotifierFunc is an adapter to use an ordinary function as UsageNotifier.
+type UsageNotifierFunc func(ctx context.Context, alerts []UsageAlert) error
+
+// NotifyUsageAlerts calls f(ctx, alerts).
+func (f UsageNotifierFunc) NotifyUsageAlerts(ctx context.Context, alerts []UsageAlert) error {
+	return f(ctx, alerts)
+}
+
+// UsageMonitor compares the usage of the current month against budgets and detects anomalies.
+type UsageMonitor struct {
+	// Level of the usage query, either ProjectLevelUsage or ContractLevelUsage.
+	Level usageQueryLevel
+
+	// Price list used to turn usage into costs.
+	PriceList PriceList
+
+	// Budgets to check.
+	Budgets []UsageBudget
+
+	// A UsageSpikeAlert is raised if the cost of a resource on the last complete day is more than
+	// SpikeFactor times the cost of the day before. Leave it 0 to use 2.
+	SpikeFactor float64
+
+	// Spikes and new products with a daily cost below MinAlertCost are ignored.
+	MinAlertCost float64
+
+	// Number of last complete days used to forecast the cost of the month. Leave it 0 to use 7.
+	TrendDays int
+
+	// Notifier of the alerts. Optional.
+	Notifier UsageNotifier
+}
+
+// BudgetStatus represents the state of a budget in the current month.
+type BudgetStatus struct {
+	// The budget.
+	Budget UsageBudget
+
+	// Cost so far.
+	Spent float64
+
+	// Forecast cost of the whole month.
+	Forecast float64
+}
+
+// UsageAlert represents a budget violation or a usage anomaly.
+type UsageAlert struct {
+	// Kind of the alert.
+	Kind UsageAlertKind
+
+	// Name of the budget. Only set for budget alerts.
+	Budget string
+
+	// Type of the resource. Only set for spike alerts.
+	ResourceType string
+
+	// The UUID of the resource. Only set for spike alerts.
+	ObjectUUID string
+
+	// Name of the resource. Only set for spike alerts.
+	Name string
+
+	// Number of the new product. Only set for new product alerts.
+	ProductNumber int
+
+	// Cost causing the alert: the spent or forecast cost of the budget, or the daily cost
+	// of the resource or product.
+	Cost float64
+
+	// The exceeded threshold: the budget limit, or the daily cost before the spike.
+	Threshold float64
+
+	// Human-readable description.
+	Message string
+}
+
+// UsageMonitorReport represents the result of a usage check.
+type UsageMonitorReport struct {
+	// Start of the checked month.
+	PeriodStart time.Time
+
+	// End of the checked month.
+	PeriodEnd time.Time
+
+	// State of all budgets.
+	Budgets []BudgetStatus
+
+	// The raised alerts.
+	Alerts []UsageAlert
+}
+
+// MonitorUsage retrieves the usage of all resources in the current month, checks it with the monitor
+// and sends the alerts to the notifier of the monitor.
+func (c *Client) MonitorUsage(ctx context.Context, monitor UsageMonitor) (UsageMonitorReport, error) {
+	now := time.Now().UTC()
+	resources, err := c.GetAllResourcesUsage(ctx, UsageQuery{
+		Level:    monitor.Level,
+		From:     usageMonthStart(now),
+		To:       now,
+		Interval: DayIntervalVariable,
+	})
+	if err != nil {
+		return UsageMonitorReport{}, err
+	}
+	report, err := monitor.Check(resources, now)
+	if err != nil {
+		return report, err
+	}
+	if len(report.Alerts) > 0 && monitor.Notifier != nil {
+		err = monitor.Notifier.NotifyUsageAlerts(ctx, report.Alerts)
+		if err != nil {
+			return report, fmt.Errorf("failed to notify usage alerts: %v", err)
+		}
+	}
+	return report, nil
+}
+
+// Check compares the usage of resources in the month of now against the budgets and detects anomalies.
+// The usage should be in daily intervals. It does not send any notifications.
+func (m UsageMonitor) Check(resources []ResourceUsage, now time.Time) (UsageMonitorReport, error) {
+	for _, budget := range m.Budgets {
+		switch budget.Scope {
+		case ProjectBudgetScope, LabelBudgetScope, ResourceTypeBudgetScope:
+		default:
+			return UsageMonitorReport{}, fmt.Errorf("scope of budget %q is invalid", budget.Name)
+		}
+		if budget.Limit <= 0 {
+			return UsageMonitorReport{}, fmt.Errorf("limit of budget %q must be positive", budget.Name)
+		}
+	}
+	if m.SpikeFactor < 0 || m.TrendDays < 0 {
+		return UsageMonitorReport{}, errors.New("'SpikeFactor' and 'TrendDays' must not be negative")
+	}
+	if m.SpikeFactor == 0 {
+		m.SpikeFactor = defaultUsageSpikeFactor
+	}
+	if m.TrendDays == 0 {
+		m.TrendDays = defaultUsageTrendDays
+	}
+	now = now.UTC()
+	report := UsageMonitorReport{
+		PeriodStart: usageMonthStart(now),
+		PeriodEnd:   usageMonthStart(now).AddDate(0, 1, 0),
+	}
+	today := now.Truncate(24 * time.Hour)
+	lastDay := today.AddDate(0, 0, -1)
+
+	// Daily costs of each resource and each product in the current month.
+	resourceCosts := make([]map[time.Time]float64, len(resources))
+	productCosts := make(map[int]map[time.Time]float64)
+	for i, resource := range resources {
+		resourceCosts[i] = make(map[time.Time]float64)
+		for _, interval := range resource.UsagePerInterval {
+			day := interval.IntervalStart.UTC().Truncate(24 * time.Hour)
+			if day.Before(report.PeriodStart) || !day.Before(report.PeriodEnd) {
+				continue
+			}
+			cost, _ := m.PriceList.UsageCost(interval.AccumulatedUsage)
+			resourceCosts[i][day] += cost
+			for _, usage := range interval.AccumulatedUsage {
+				if productCosts[usage.ProductNumber] == nil {
+					productCosts[usage.ProductNumber] = make(map[time.Time]float64)
+				}
+				productCost, _ := m.PriceList.UsageCost([]Usage{usage})
+				productCosts[usage.ProductNumber][day] += productCost
+			}
+		}
+	}
+
+	for _, budget := range m.Budgets {
+		dailyCosts := make(map[time.Time]float64)
+		for i, resource := range resources {
+			if !budget.matches(resource) {
+				continue
+			}
+			for day, cost := range resourceCosts[i] {
+				dailyCosts[day] += cost
+			}
+		}
+		status := BudgetStatus{Budget: budget}
+		for _, cost := range dailyCosts {
+			status.Spent += cost
+		}
+		status.Forecast = status.Spent + m.dailyCostTrend(dailyCosts, report.PeriodStart, today, now)*report.PeriodEnd.Sub(now).Hours()/24
+		report.Budgets = append(report.Budgets, status)
+		switch {
+		case status.Spent >= budget.Limit:
+			report.Alerts = append(report.Alerts, UsageAlert{
+				Kind:      BudgetExceededAlert,
+				Budget:    budget.Name,
+				Cost:      status.Spent,
+				Threshold: budget.Limit,
+				Message: fmt.Sprintf("budget %q is exceeded: spent %.2f %s of %.2f %s",
+					budget.Name, status.Spent, m.PriceList.Currency, budget.Limit, m.PriceList.Currency),
+			})
+		case status.Forecast >= budget.Limit:
+			report.Alerts = append(report.Alerts, UsageAlert{
+				Kind:      BudgetForecastAlert,
+				Budget:    budget.Name,
+				Cost:      status.Forecast,
+				Threshold: budget.Limit,
+				Message: fmt.Sprintf("budget %q is forecast to be exceeded: %.2f %s of %.2f %s by the end of the month",
+					budget.Name, status.Forecast, m.PriceList.Currency, budget.Limit, m.PriceList.Currency),
+			})
+		}
+	}
+
+	// Anomalies are detected on the last complete day, today is still incomplete.
+	if lastDay.Before(report.PeriodStart) {
+		return report, nil
+	}
+	for i, resource := range resources {
+		cost := resourceCosts[i][lastDay]
+		previousCost := resourceCosts[i][lastDay.AddDate(0, 0, -1)]
+		if previousCost > 0 && cost >= m.MinAlertCost && cost > m.SpikeFactor*previousCost {
+			report.Alerts = append(report.Alerts, UsageAlert{
+				Kind:         UsageSpikeAlert,
+				ResourceType: resource.ResourceType,
+				ObjectUUID:   resource.ObjectUUID,
+				Name:         resource.Name,
+				Cost:         cost,
+				Threshold:    previousCost,
+				Message: fmt.Sprintf("daily cost of %s %q rose from %.2f %s to %.2f %s",
+					resource.ResourceType, resource.Name, previousCost, m.PriceList.Currency, cost, m.PriceList.Currency),
+			})
+		}
+	}
+	// Without usage before the last complete day, every product would be new.
+	hasEarlierUsage := false
+	for _, dailyCosts := range productCosts {
+		for day := range dailyCosts {
+			if day.Before(lastDay) {
+				hasEarlierUsage = true
+			}
+		}
+	}
+	if !hasEarlierUsage {
+		return report, nil
+	}
+	var productNumbers []int
+	for productNumber := range productCosts {
+		productNumbers = append(productNumbers, productNumber)
+	}
+	sort.Ints(productNumbers)
+	for _, productNumber := range productNumbers {
+		dailyCosts := productCosts[productNumber]
+		cost, usedOnLastDay := dailyCosts[lastDay]
+		if !usedOnLastDay || cost < m.MinAlertCost {
+			continue
+		}
+		isNew := true
+		for day := range dailyCosts {
+			if day.Before(lastDay) {
+				isNew = false
+				break
+			}
+		}
+		if isNew {
+			report.Alerts = append(report.Alerts, UsageAlert{
+				Kind:          NewProductAlert,
+				ProductNumber: productNumber,
+				Cost:          cost,
+				Message: fmt.Sprintf("product %d is used for the first time this month at %.2f %s per day",
+					productNumber, cost, m.PriceList.Currency),
+			})
+		}
+	}
+	return report, nil
+}
+
+// dailyCostTrend returns the average daily cost of the last complete days. If there is no complete day yet,
+// the cost so far is averaged over the elapsed time.
+func (m UsageMonitor) dailyCostTrend(dailyCosts map[time.Time]float64, periodStart, today, now time.Time) float64 {
+	trendStart := today.AddDate(0, 0, -m.TrendDays)
+	if trendStart.Before(periodStart) {
+		trendStart = periodStart
+	}
+	days := today.Sub(trendStart).Hours() / 24
+	if days <= 0 {
+		elapsedDays := now.Sub(periodStart).Hours() / 24
+		if elapsedDays <= 0 {
+			return 0
+		}
+		var spent float64
+		for _, cost := range dailyCosts {
+			spent += cost
+		}
+		return spent / elapsedDays
+	}
+	var cost float64
+	for day, dailyCost := range dailyCosts {
+		if !day.Before(trendStart) && day.Before(today) {
+			cost += dailyCost
+		}
+	}
+	return cost / days
+}
+
+// matches checks if a resource is covered by the budget.
+func (b UsageBudget) matches(resource ResourceUsage) bool {
+	switch b.Scope {
+	case ProjectBudgetScope:
+		return resource.ProjectUUID == b.Value
+	case LabelBudgetScope:
+		return hasLabel(resource.Labels, b.Value)
+	case ResourceTypeBudgetScope:
+		return resource.ResourceType == b.Value
+	}
+	return false
+}
+
+// usageMonthStart returns the start of the month of t in UTC.
+func usageMonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
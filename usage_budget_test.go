@@ -0,0 +1,180 @@
+package gsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageMonitor_Check(t *testing.T) {
+	priceList, err := NewPriceList("EUR",
+		ProductPrice{ProductNumber: 1, UnitPrice: 0.5},
+		ProductPrice{ProductNumber: 10, UnitPrice: 1},
+		ProductPrice{ProductNumber: 20, UnitPrice: 3},
+	)
+	assert.Nil(t, err, "NewPriceList returned an error %v", err)
+	march1 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	resources := []ResourceUsage{
+		{
+			ResourceType:     RocketStorageUsageResource,
+			ObjectUUID:       dummyUUID,
+			Name:             "fleet",
+			Labels:           []string{"team=shop"},
+			UsagePerInterval: prepareDailyUsage(march1.AddDate(0, 0, -1), 10, 100, 1, 1, 1, 5),
+		},
+		{
+			ResourceType:     ServerUsageResource,
+			Name:             "web",
+			UsagePerInterval: prepareDailyUsage(march1, 1, 2, 2, 2, 2),
+		},
+		{
+			ResourceType:     SnapshotUsageResource,
+			Name:             "new",
+			ProjectUUID:      dummyUUID,
+			UsagePerInterval: prepareDailyUsage(march1.AddDate(0, 0, 3), 20, 1),
+		},
+	}
+	monitor := UsageMonitor{
+		PriceList: priceList,
+		Budgets: []UsageBudget{
+			{Name: "rocket", Scope: ResourceTypeBudgetScope, Value: RocketStorageUsageResource, Limit: 5},
+			{Name: "shop", Scope: LabelBudgetScope, Value: "team=shop", Limit: 100},
+			{Name: "project", Scope: ProjectBudgetScope, Value: dummyUUID, Limit: 20},
+		},
+		MinAlertCost: 2,
+	}
+	report, err := monitor.Check(resources, march1.Add(4*24*time.Hour+12*time.Hour))
+	assert.Nil(t, err, "Check returned an error %v", err)
+	assert.Equal(t, march1, report.PeriodStart)
+	assert.Equal(t, march1.AddDate(0, 1, 0), report.PeriodEnd)
+	assert.Equal(t, []BudgetStatus{
+		{Budget: monitor.Budgets[0], Spent: 8, Forecast: 61},
+		{Budget: monitor.Budgets[1], Spent: 8, Forecast: 61},
+		{Budget: monitor.Budgets[2], Spent: 3, Forecast: 22.875},
+	}, report.Budgets)
+	var kinds []UsageAlertKind
+	for _, alert := range report.Alerts {
+		kinds = append(kinds, alert.Kind)
+	}
+	assert.Equal(t, []UsageAlertKind{BudgetExceededAlert, BudgetForecastAlert, UsageSpikeAlert, NewProductAlert}, kinds)
+	assert.Equal(t, "rocket", report.Alerts[0].Budget)
+	assert.Equal(t, "project", report.Alerts[1].Budget)
+	assert.Equal(t, UsageAlert{
+		Kind:         UsageSpikeAlert,
+		ResourceType: RocketStorageUsageResource,
+		ObjectUUID:   dummyUUID,
+		Name:         "fleet",
+		Cost:         5,
+		Threshold:    1,
+		Message:      `daily cost of rocket_storages "fleet" rose from 1.00 EUR to 5.00 EUR`,
+	}, report.Alerts[2])
+	assert.Equal(t, 20, report.Alerts[3].ProductNumber)
+	assert.Equal(t, 3.0, report.Alerts[3].Cost)
+
+	monitor.MinAlertCost = 10
+	report, err = monitor.Check(resources, march1.Add(4*24*time.Hour+12*time.Hour))
+	assert.Nil(t, err, "Check returned an error %v", err)
+	assert.Equal(t, 2, len(report.Alerts))
+
+	// Without a complete day, the cost so far is extrapolated.
+	firstDay := []ResourceUsage{{ResourceType: ServerUsageResource, UsagePerInterval: prepareDailyUsage(march1, 1, 2)}}
+	report, err = monitor.Check(firstDay, march1.Add(6*time.Hour))
+	assert.Nil(t, err, "Check returned an error %v", err)
+	assert.Equal(t, []BudgetStatus{
+		{Budget: monitor.Budgets[0]},
+		{Budget: monitor.Budgets[1]},
+		{Budget: monitor.Budgets[2]},
+	}, report.Budgets)
+	monitor.Budgets = []UsageBudget{{Name: "servers", Scope: ResourceTypeBudgetScope, Value: ServerUsageResource, Limit: 200}}
+	report, err = monitor.Check(firstDay, march1.Add(6*time.Hour))
+	assert.Nil(t, err, "Check returned an error %v", err)
+	assert.Equal(t, 1.0+4*30.75, report.Budgets[0].Forecast)
+	assert.Empty(t, report.Alerts)
+
+	invalidMonitors := []UsageMonitor{
+		{Budgets: []UsageBudget{{Name: "invalid", Scope: "contract", Limit: 1}}},
+		{Budgets: []UsageBudget{{Name: "invalid", Scope: LabelBudgetScope, Value: "team=shop"}}},
+		{SpikeFactor: -1},
+	}
+	for _, invalidMonitor := range invalidMonitors {
+		_, err = invalidMonitor.Check(resources, march1)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestUsageBudget_matches(t *testing.T) {
+	budget := UsageBudget{Name: "project", Scope: ProjectBudgetScope, Value: dummyUUID, Limit: 1}
+	// Servers, storages, storage backups and load balancers have no project UUID and never match a project budget.
+	var resources []ResourceUsage
+	resources = append(resources, ServersUsage{ResourcesUsage: []ServerUsageProperties{{ObjectUUID: dummyUUID}}}.Resources()...)
+	resources = append(resources, DistributedStoragesUsage{ResourcesUsage: []StorageUsageProperties{{ObjectUUID: dummyUUID}}}.Resources()...)
+	resources = append(resources, StorageBackupsUsage{ResourcesUsage: []StorageBackupUsageProperties{{ObjectUUID: dummyUUID}}}.Resources()...)
+	resources = append(resources, LoadBalancersUsage{ResourcesUsage: []LoadBalancerUsageProperties{{ObjectUUID: dummyUUID}}}.Resources()...)
+	for _, resource := range resources {
+		assert.False(t, budget.matches(resource), "%s matches a project budget", resource.ResourceType)
+	}
+	paaSServices := PaaSServicesUsage{ResourcesUsage: []PaaSServiceUsageProperties{{ObjectUUID: dummyUUID, ProjectUUID: dummyUUID}}}
+	assert.True(t, budget.matches(paaSServices.Resources()[0]))
+}
+
+func TestClient_MonitorUsage(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	monthStart := usageMonthStart(time.Now())
+	mux.HandleFunc(apiProjectLevelUsage+"/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, DayIntervalVariable, r.URL.Query().Get("interval_variable"))
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		if strings.HasSuffix(r.URL.Path, RocketStorageUsageResource) {
+			res, _ := json.Marshal(RocketStoragesUsage{ResourcesUsage: []StorageUsageProperties{{
+				ObjectUUID:       dummyUUID,
+				Name:             "fleet",
+				UsagePerInterval: prepareDailyUsage(monthStart, 10, 5),
+			}}})
+			fmt.Fprint(w, string(res))
+			return
+		}
+		fmt.Fprint(w, "{}")
+	})
+	priceList, err := NewPriceList("EUR", ProductPrice{ProductNumber: 10, UnitPrice: 1})
+	assert.Nil(t, err, "NewPriceList returned an error %v", err)
+	var notified []UsageAlert
+	monitor := UsageMonitor{
+		PriceList: priceList,
+		Budgets:   []UsageBudget{{Name: "rocket", Scope: ResourceTypeBudgetScope, Value: RocketStorageUsageResource, Limit: 1}},
+		Notifier: UsageNotifierFunc(func(ctx context.Context, alerts []UsageAlert) error {
+			notified = alerts
+			return nil
+		}),
+	}
+	report, err := client.MonitorUsage(emptyCtx, monitor)
+	assert.Nil(t, err, "MonitorUsage returned an error %v", err)
+	assert.Equal(t, 5.0, report.Budgets[0].Spent)
+	assert.Equal(t, report.Alerts, notified)
+	assert.Equal(t, BudgetExceededAlert, notified[0].Kind)
+
+	monitor.Notifier = UsageNotifierFunc(func(ctx context.Context, alerts []UsageAlert) error {
+		return fmt.Errorf("mail server unavailable")
+	})
+	_, err = client.MonitorUsage(emptyCtx, monitor)
+	assert.NotNil(t, err)
+}
+
+func prepareDailyUsage(start time.Time, productNumber int, values ...int) []UsagePerInterval {
+	var intervals []UsagePerInterval
+	for i, value := range values {
+		day := start.AddDate(0, 0, i)
+		intervals = append(intervals, UsagePerInterval{
+			IntervalStart:    GSTime{day},
+			IntervalEnd:      GSTime{day.AddDate(0, 0, 1)},
+			AccumulatedUsage: []Usage{{ProductNumber: productNumber, Value: value}},
+		})
+	}
+	return intervals
+}
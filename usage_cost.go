@@ -0,0 +1,471 @@
+package gsclient
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Resource types of ResourceUsage.
+const (
+	ServerUsageResource             = "servers"
+	DistributedStorageUsageResource = "distributed_storages"
+	RocketStorageUsageResource      = "rocket_storages"
+	StorageBackupUsageResource      = "storage_backups"
+	SnapshotUsageResource           = "snapshots"
+	TemplateUsageResource           = "templates"
+	ISOImageUsageResource           = "iso_images"
+	IPUsageResource                 = "ip_addresses"
+	LoadBalancerUsageResource       = "load_balancers"
+	PaaSServiceUsageResource        = "paas_services"
+)
+
+// ProductPrice represents the price of a product.
+type ProductPrice struct {
+	// Number of the product, as in Usage.ProductNumber.
+	ProductNumber int `json:"product_number"`
+
+	// Name of the product. Optional.
+	Name string `json:"name,omitempty"`
+
+	// Price per unit of the usage value, e.g. per core-minute.
+	UnitPrice float64 `json:"unit_price"`
+}
+
+// PriceList represents a price catalogue keyed by product number.
+type PriceList struct {
+	// Currency of the prices, e.g. "EUR".
+	Currency string
+
+	prices map[int]ProductPrice
+}
+
+// priceListJSON is the JSON representation of a price list.
+type priceListJSON struct {
+	Currency string         `json:"currency"`
+	Products []ProductPrice `json:"products"`
+}
+
+// NewPriceList returns a price list of the given products. Product numbers must be unique and prices must not be negative.
+func NewPriceList(currency string, products ...ProductPrice) (PriceList, error) {
+	priceList := PriceList{
+		Currency: currency,
+		prices:   make(map[int]ProductPrice),
+	}
+	for _, product := range products {
+		if _, ok := priceList.prices[product.ProductNumber]; ok {
+			return PriceList{}, fmt.Errorf("product %d has several prices", product.ProductNumber)
+		}
+		if product.UnitPrice < 0 {
+			return PriceList{}, fmt.Errorf("price of product %d is negative", product.ProductNumber)
+		}
+		priceList.prices[product.ProductNumber] = product
+	}
+	return priceList, nil
+}
+
+// LoadPriceListJSON reads a price list in JSON format, e.g.
+// {"currency": "EUR", "products": [{"product_number": 10000, "name": "Core", "unit_price": 0.0002}]}.
+func LoadPriceListJSON(r io.Reader) (PriceList, error) {
+	var priceList priceListJSON
+	err := json.NewDecoder(r).Decode(&priceList)
+	if err != nil {
+		return PriceList{}, fmt.Errorf("price list is invalid: %v", err)
+	}
+	return NewPriceList(priceList.Currency, priceList.Products...)
+}
+
+// LoadPriceListCSV reads a price list in CSV format. The first row is a header with the columns
+// "product_number" and "unit_price", and optionally "name". Other columns are ignored.
+func LoadPriceListCSV(r io.Reader, currency string) (PriceList, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return PriceList{}, fmt.Errorf("price list is invalid: %v", err)
+	}
+	if len(records) == 0 {
+		return PriceList{}, errors.New("price list has no header")
+	}
+	columns := make(map[string]int)
+	for i, column := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	numberColumn, hasNumber := columns["product_number"]
+	priceColumn, hasPrice := columns["unit_price"]
+	if !hasNumber || !hasPrice {
+		return PriceList{}, errors.New("price list header must contain the columns product_number and unit_price")
+	}
+	nameColumn, hasName := columns["name"]
+	var products []ProductPrice
+	for i, record := range records[1:] {
+		line := i + 2
+		if len(record) <= numberColumn || len(record) <= priceColumn {
+			return PriceList{}, fmt.Errorf("line %d: too few columns", line)
+		}
+		var product ProductPrice
+		product.ProductNumber, err = strconv.Atoi(strings.TrimSpace(record[numberColumn]))
+		if err != nil {
+			return PriceList{}, fmt.Errorf("line %d: product number is invalid: %v", line, err)
+		}
+		product.UnitPrice, err = strconv.ParseFloat(strings.TrimSpace(record[priceColumn]), 64)
+		if err != nil {
+			return PriceList{}, fmt.Errorf("line %d: unit price is invalid: %v", line, err)
+		}
+		if hasName && len(record) > nameColumn {
+			product.Name = strings.TrimSpace(record[nameColumn])
+		}
+		products = append(products, product)
+	}
+	return NewPriceList(currency, products...)
+}
+
+// Price returns the price of a product, and false if the product has no price.
+func (p PriceList) Price(productNumber int) (ProductPrice, bool) {
+	price, ok := p.prices[productNumber]
+	return price, ok
+}
+
+// UsageCost returns the cost of usages, along with the numbers of products without price, which are not included.
+func (p PriceList) UsageCost(usages []Usage) (float64, []int) {
+	var cost float64
+	var unpriced []int
+	for _, usage := range usages {
+		price, ok := p.prices[usage.ProductNumber]
+		if !ok {
+			unpriced = append(unpriced, usage.ProductNumber)
+			continue
+		}
+		cost += float64(usage.Value) * price.UnitPrice
+	}
+	return cost, unpriced
+}
+
+// ResourceUsage represents the usage of a single resource of any type, see the Resources methods of
+// the usage types, e.g. ServersUsage.Resources.
+type ResourceUsage struct {
+	// Type of the resource, e.g. ServerUsageResource.
+	ResourceType string
+
+	// The UUID of the resource.
+	ObjectUUID string
+
+	// The name of the resource.
+	Name string
+
+	// List of labels. Always empty for resource types without labels, e.g. load balancers.
+	Labels []string
+
+	// The UUID of the project of the resource. Empty for resource types without project, e.g. servers.
+	ProjectUUID string
+
+	// True if the resource is deleted.
+	Deleted bool
+
+	// Usage of the resource within the intervals.
+	UsagePerInterval []UsagePerInterval
+}
+
+// IntervalCost represents the cost within an interval.
+type IntervalCost struct {
+	// Start of the interval.
+	IntervalStart GSTime
+
+	// End of the interval.
+	IntervalEnd GSTime
+
+	// Cost within the interval.
+	Cost float64
+}
+
+// ResourceCost represents the cost of a single resource.
+type ResourceCost struct {
+	// Type of the resource, e.g. ServerUsageResource.
+	ResourceType string
+
+	// The UUID of the resource.
+	ObjectUUID string
+
+	// The name of the resource.
+	Name string
+
+	// List of labels.
+	Labels []string
+
+	// Total cost of the resource.
+	Cost float64
+
+	// Cost of the resource per interval.
+	Intervals []IntervalCost
+}
+
+// CostEstimate represents the cost of resources, estimated from their usage and a price list.
+type CostEstimate struct {
+	// Currency of the costs.
+	Currency string
+
+	// Cost per resource, sorted by resource type and name.
+	Resources []ResourceCost
+
+	// Cost of all resources per interval, sorted by interval start.
+	Intervals []IntervalCost
+
+	// Total cost of all resources.
+	Total float64
+
+	// Numbers of products without price, which are not included in the costs.
+	UnpricedProducts []int
+}
+
+// EstimateCost turns the usage of resources into costs per resource and per interval.
+func (p PriceList) EstimateCost(resources []ResourceUsage) CostEstimate {
+	estimate := CostEstimate{Currency: p.Currency}
+	intervals := make(map[int64]*IntervalCost)
+	unpricedProducts := make(map[int]bool)
+	for _, resource := range resources {
+		resourceCost := ResourceCost{
+			ResourceType: resource.ResourceType,
+			ObjectUUID:   resource.ObjectUUID,
+			Name:         resource.Name,
+			Labels:       resource.Labels,
+		}
+		for _, interval := range resource.UsagePerInterval {
+			cost, unpriced := p.UsageCost(interval.AccumulatedUsage)
+			for _, productNumber := range unpriced {
+				unpricedProducts[productNumber] = true
+			}
+			resourceCost.Cost += cost
+			resourceCost.Intervals = append(resourceCost.Intervals, IntervalCost{
+				IntervalStart: interval.IntervalStart,
+				IntervalEnd:   interval.IntervalEnd,
+				Cost:          cost,
+			})
+			key := interval.IntervalStart.Unix()
+			if _, ok := intervals[key]; !ok {
+				intervals[key] = &IntervalCost{IntervalStart: interval.IntervalStart, IntervalEnd: interval.IntervalEnd}
+			}
+			intervals[key].Cost += cost
+		}
+		estimate.Resources = append(estimate.Resources, resourceCost)
+		estimate.Total += resourceCost.Cost
+	}
+	sort.SliceStable(estimate.Resources, func(i, j int) bool {
+		if estimate.Resources[i].ResourceType != estimate.Resources[j].ResourceType {
+			return estimate.Resources[i].ResourceType < estimate.Resources[j].ResourceType
+		}
+		return estimate.Resources[i].Name < estimate.Resources[j].Name
+	})
+	for _, interval := range intervals {
+		estimate.Intervals = append(estimate.Intervals, *interval)
+	}
+	sort.Slice(estimate.Intervals, func(i, j int) bool {
+		return estimate.Intervals[i].IntervalStart.Before(estimate.Intervals[j].IntervalStart.Time)
+	})
+	for productNumber := range unpricedProducts {
+		estimate.UnpricedProducts = append(estimate.UnpricedProducts, productNumber)
+	}
+	sort.Ints(estimate.UnpricedProducts)
+	return estimate
+}
+
+// CostByLabel returns the cost per label value, e.g. per team for the key "team" and labels like "team=shop"
+// or "team:shop". Leave the key empty to group by the full labels. The cost of a resource with several
+// matching labels is split evenly between them, the cost of resources without matching label is returned
+// for the empty label.
+func (e CostEstimate) CostByLabel(key string) map[string]float64 {
+	costs := make(map[string]float64)
+	for _, resource := range e.Resources {
+		labels := labelValues(resource.Labels, key)
+		if len(labels) == 0 {
+			costs[""] += resource.Cost
+			continue
+		}
+		for _, label := range labels {
+			costs[label] += resource.Cost / float64(len(labels))
+		}
+	}
+	return costs
+}
+
+// labelValues returns the values of the labels with the given key, which are separated from the key
+// by "=" or ":". If the key is empty, all labels are returned.
+func labelValues(labels []string, key string) []string {
+	if key == "" {
+		return labels
+	}
+	var values []string
+	for _, label := range labels {
+		for _, separator := range []string{"=", ":"} {
+			if strings.HasPrefix(label, key+separator) {
+				values = append(values, strings.TrimPrefix(label, key+separator))
+				break
+			}
+		}
+	}
+	return values
+}
+
+// Resources returns the usage of the servers as ResourceUsage.
+func (u ServersUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     ServerUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the distributed storages as ResourceUsage.
+func (u DistributedStoragesUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     DistributedStorageUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the rocket storages as ResourceUsage.
+func (u RocketStoragesUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     RocketStorageUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the storage backups as ResourceUsage.
+func (u StorageBackupsUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     StorageBackupUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the snapshots as ResourceUsage.
+func (u SnapshotsUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     SnapshotUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			ProjectUUID:      usage.ProjectUUID,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the templates as ResourceUsage.
+func (u TemplatesUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     TemplateUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			ProjectUUID:      usage.ProjectUUID,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the ISO images as ResourceUsage.
+func (u ISOImagesUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     ISOImageUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			ProjectUUID:      usage.ProjectUUID,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the IP addresses as ResourceUsage.
+func (u IPsUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     IPUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			Labels:           usage.Labels,
+			ProjectUUID:      usage.ProjectUUID,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the load balancers as ResourceUsage.
+func (u LoadBalancersUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     LoadBalancerUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
+
+// Resources returns the usage of the PaaS services as ResourceUsage.
+func (u PaaSServicesUsage) Resources() []ResourceUsage {
+	var resources []ResourceUsage
+	for _, usage := range u.ResourcesUsage {
+		resources = append(resources, ResourceUsage{
+			ResourceType:     PaaSServiceUsageResource,
+			ObjectUUID:       usage.ObjectUUID,
+			Name:             usage.Name,
+			ProjectUUID:      usage.ProjectUUID,
+			Deleted:          usage.Deleted,
+			UsagePerInterval: usage.UsagePerInterval,
+		})
+	}
+	return resources
+}
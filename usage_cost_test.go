@@ -0,0 +1,117 @@
+package gsclient
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPriceListJSON(t *testing.T) {
+	priceList, err := LoadPriceListJSON(strings.NewReader(`{"currency": "EUR", "products": [
+		{"product_number": 1, "name": "Core", "unit_price": 0.5},
+		{"product_number": 2, "unit_price": 0.25}
+	]}`))
+	assert.Nil(t, err, "LoadPriceListJSON returned an error %v", err)
+	assert.Equal(t, "EUR", priceList.Currency)
+	price, ok := priceList.Price(1)
+	assert.True(t, ok)
+	assert.Equal(t, ProductPrice{ProductNumber: 1, Name: "Core", UnitPrice: 0.5}, price)
+	_, ok = priceList.Price(3)
+	assert.False(t, ok)
+
+	invalidPriceLists := []string{
+		`{"products": [`,
+		`{"products": [{"product_number": 1, "unit_price": 0.5}, {"product_number": 1, "unit_price": 0.25}]}`,
+		`{"products": [{"product_number": 1, "unit_price": -0.5}]}`,
+	}
+	for _, invalidPriceList := range invalidPriceLists {
+		_, err = LoadPriceListJSON(strings.NewReader(invalidPriceList))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestLoadPriceListCSV(t *testing.T) {
+	priceList, err := LoadPriceListCSV(strings.NewReader("Name,Product_Number,Unit_Price,Unit\nCore,1,0.5,minute\nMemory,2, 0.25 ,GB-minute\n"), "EUR")
+	assert.Nil(t, err, "LoadPriceListCSV returned an error %v", err)
+	assert.Equal(t, "EUR", priceList.Currency)
+	price, ok := priceList.Price(2)
+	assert.True(t, ok)
+	assert.Equal(t, ProductPrice{ProductNumber: 2, Name: "Memory", UnitPrice: 0.25}, price)
+
+	invalidPriceLists := []string{
+		"",
+		"name,unit_price\nCore,0.5\n",
+		"product_number,unit_price\none,0.5\n",
+		"product_number,unit_price\n1,free\n",
+		"product_number,unit_price\n1\n",
+	}
+	for _, invalidPriceList := range invalidPriceLists {
+		_, err = LoadPriceListCSV(strings.NewReader(invalidPriceList), "EUR")
+		assert.NotNil(t, err)
+	}
+}
+
+func TestPriceList_EstimateCost(t *testing.T) {
+	priceList, err := NewPriceList("EUR",
+		ProductPrice{ProductNumber: 1, UnitPrice: 0.5},
+		ProductPrice{ProductNumber: 2, UnitPrice: 0.25},
+	)
+	assert.Nil(t, err, "NewPriceList returned an error %v", err)
+	day1 := GSTime{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	day2 := GSTime{time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}
+	day3 := GSTime{time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)}
+	serversUsage := ServersUsage{ResourcesUsage: []ServerUsageProperties{
+		{
+			ObjectUUID: dummyUUID,
+			Name:       "web",
+			Labels:     []string{"team:shop", "env:prod"},
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 10}, {ProductNumber: 2, Value: 4}}},
+				{IntervalStart: day2, IntervalEnd: day3, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 2}, {ProductNumber: 9, Value: 100}}},
+			},
+		},
+		{
+			ObjectUUID: "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c",
+			Name:       "db",
+			Labels:     []string{"team:shop", "team=data"},
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 4}}},
+			},
+		},
+	}}
+	loadBalancersUsage := LoadBalancersUsage{ResourcesUsage: []LoadBalancerUsageProperties{
+		{
+			ObjectUUID: dummyUUID,
+			Name:       "lb",
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day2, IntervalEnd: day3, AccumulatedUsage: []Usage{{ProductNumber: 2, Value: 8}}},
+			},
+		},
+	}}
+	resources := append(serversUsage.Resources(), loadBalancersUsage.Resources()...)
+	estimate := priceList.EstimateCost(resources)
+	assert.Equal(t, "EUR", estimate.Currency)
+	assert.Equal(t, 11.0, estimate.Total)
+	assert.Equal(t, []int{9}, estimate.UnpricedProducts)
+
+	var names []string
+	for _, resource := range estimate.Resources {
+		names = append(names, resource.Name)
+	}
+	assert.Equal(t, []string{"lb", "db", "web"}, names)
+	assert.Equal(t, LoadBalancerUsageResource, estimate.Resources[0].ResourceType)
+	assert.Equal(t, 7.0, estimate.Resources[2].Cost)
+	assert.Equal(t, []IntervalCost{
+		{IntervalStart: day1, IntervalEnd: day2, Cost: 6},
+		{IntervalStart: day2, IntervalEnd: day3, Cost: 1},
+	}, estimate.Resources[2].Intervals)
+	assert.Equal(t, []IntervalCost{
+		{IntervalStart: day1, IntervalEnd: day2, Cost: 8},
+		{IntervalStart: day2, IntervalEnd: day3, Cost: 3},
+	}, estimate.Intervals)
+
+	assert.Equal(t, map[string]float64{"shop": 8, "data": 1, "": 2}, estimate.CostByLabel("team"))
+	assert.Equal(t, map[string]float64{"team:shop": 4.5, "env:prod": 3.5, "team=data": 1, "": 2}, estimate.CostByLabel(""))
+}
@@ -0,0 +1,351 @@
+package gsclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultUsageChunkRange is the default maximum time range of a single usage request.
+// Longer time ranges are split into several requests, whose results are merged.
+const DefaultUsageChunkRange = 31 * 24 * time.Hour
+
+// UsageResult is the set of usage types returned by GetUsage.
+type UsageResult interface {
+	GeneralUsage | ServersUsage | DistributedStoragesUsage | RocketStoragesUsage | StorageBackupsUsage |
+		SnapshotsUsage | TemplatesUsage | ISOImagesUsage | IPsUsage | LoadBalancersUsage | PaaSServicesUsage
+}
+
+// UsageQuery represents a query for the usage of resources.
+type UsageQuery struct {
+	// Level of the query, either ProjectLevelUsage or ContractLevelUsage.
+	Level usageQueryLevel
+
+	// Start of the time range.
+	From time.Time
+
+	// End of the time range. Leave it zero to use the current time.
+	To time.Time
+
+	// If true, deleted resources are excluded.
+	WithoutDeleted bool
+
+	// Interval of the usage per interval: HourIntervalVariable, DayIntervalVariable, WeekIntervalVariable,
+	// MonthIntervalVariable, or empty.
+	Interval string
+
+	// Maximum time range of a single request. Leave it 0 to use DefaultUsageChunkRange.
+	// Chunks are aligned to the interval, so that an interval is never split between two requests.
+	ChunkRange time.Duration
+}
+
+// Validate checks if the usage query is valid.
+func (q UsageQuery) Validate() error {
+	if q.Level != ProjectLevelUsage && q.Level != ContractLevelUsage {
+		return invalidUsageQueryLevel
+	}
+	if q.From.IsZero() {
+		return errors.New("'From' is required")
+	}
+	if !q.To.IsZero() && !q.From.Before(q.To) {
+		return errors.New("'From' must be before 'To'")
+	}
+	switch q.Interval {
+	case "", HourIntervalVariable, DayIntervalVariable, WeekIntervalVariable, MonthIntervalVariable:
+	default:
+		return errors.New("'Interval' must be HourIntervalVariable, DayIntervalVariable, WeekIntervalVariable, MonthIntervalVariable or empty")
+	}
+	if q.ChunkRange < 0 {
+		return errors.New("'ChunkRange' must not be negative")
+	}
+	return nil
+}
+
+// chunks splits the time range of the query into consecutive queries of at most ChunkRange,
+// aligned to the interval.
+func (q UsageQuery) chunks() []UsageQuery {
+	to := q.To
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	chunkRange := q.ChunkRange
+	if chunkRange == 0 {
+		chunkRange = DefaultUsageChunkRange
+	}
+	var chunks []UsageQuery
+	for start := q.From; start.Before(to); {
+		end := start.Add(chunkRange)
+		if aligned := alignToUsageInterval(end, q.Interval); aligned.After(start) {
+			end = aligned
+		}
+		if end.After(to) {
+			end = to
+		}
+		chunk := q
+		chunk.From = start
+		chunk.To = end
+		chunks = append(chunks, chunk)
+		start = end
+	}
+	return chunks
+}
+
+// queryParameters returns the query parameters of the usage request.
+func (q UsageQuery) queryParameters() map[string]string {
+	queryParam := map[string]string{
+		"from_time":       GSTime{q.From}.String(),
+		"without_deleted": strconv.FormatBool(q.WithoutDeleted),
+	}
+	if q.Interval != "" {
+		queryParam["interval_variable"] = q.Interval
+	}
+	if !q.To.IsZero() {
+		queryParam["to_time"] = GSTime{q.To}.String()
+	}
+	return queryParam
+}
+
+// alignToUsageInterval truncates a time to the start of its hour, day, ISO week or month (in UTC).
+// The time is returned unchanged if the interval is empty.
+func alignToUsageInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case HourIntervalVariable:
+		return t.Truncate(time.Hour)
+	case DayIntervalVariable:
+		return day
+	case WeekIntervalVariable:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case MonthIntervalVariable:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// GetUsage returns the usage of a resource type, e.g. GetUsage[ServersUsage](ctx, client, query).
+// Long time ranges are split into several requests (see UsageQuery.ChunkRange), whose results are merged:
+// the usage per interval of a resource is concatenated, or summed up to a single total if no interval is given,
+// and its other properties are taken from the last request.
+//
+// See: https://gridscale.io/en/api-documentation/index.html#operation/ProjectLevelUsageGet
+func GetUsage[T UsageResult](ctx context.Context, c *Client, query UsageQuery) (T, error) {
+	var result T
+	err := query.Validate()
+	if err != nil {
+		return result, err
+	}
+	uri := apiProjectLevelUsage
+	if query.Level == ContractLevelUsage {
+		uri = apiContractLevelUsage
+	}
+	uri = path.Join(uri, usageResourcePath(result))
+	for i, chunk := range query.chunks() {
+		r := gsRequest{
+			uri:                 uri,
+			method:              http.MethodGet,
+			skipCheckingRequest: true,
+			queryParameters:     chunk.queryParameters(),
+		}
+		var response T
+		err = r.execute(ctx, *c, &response)
+		if err != nil {
+			return result, err
+		}
+		if i == 0 {
+			result = response
+			continue
+		}
+		result = mergeUsage(result, response, query.Interval == "")
+	}
+	return result, nil
+}
+
+// usageResourcePath returns the path of a usage type relative to the usage endpoint.
+func usageResourcePath(usage interface{}) string {
+	switch usage.(type) {
+	case ServersUsage:
+		return ServerUsageResource
+	case DistributedStoragesUsage:
+		return DistributedStorageUsageResource
+	case RocketStoragesUsage:
+		return RocketStorageUsageResource
+	case StorageBackupsUsage:
+		return StorageBackupUsageResource
+	case SnapshotsUsage:
+		return SnapshotUsageResource
+	case TemplatesUsage:
+		return TemplateUsageResource
+	case ISOImagesUsage:
+		return ISOImageUsageResource
+	case IPsUsage:
+		return IPUsageResource
+	case LoadBalancersUsage:
+		return LoadBalancerUsageResource
+	case PaaSServicesUsage:
+		return PaaSServiceUsageResource
+	}
+	return ""
+}
+
+// mergeUsage merges the usage of a later time range into the usage of an earlier one.
+// If isTotal is true, the usage is summed up to a single interval covering both time ranges.
+func mergeUsage[T UsageResult](earlier, later T, isTotal bool) T {
+	var merged interface{}
+	switch e := interface{}(earlier).(type) {
+	case GeneralUsage:
+		l := interface{}(later).(GeneralUsage)
+		products := []struct{ earlier, later *ResourceUsageInfo }{
+			{&e.ResourcesUsage.Servers, &l.ResourcesUsage.Servers},
+			{&e.ResourcesUsage.RocketStorages, &l.ResourcesUsage.RocketStorages},
+			{&e.ResourcesUsage.DistributedStorages, &l.ResourcesUsage.DistributedStorages},
+			{&e.ResourcesUsage.StorageBackups, &l.ResourcesUsage.StorageBackups},
+			{&e.ResourcesUsage.Snapshots, &l.ResourcesUsage.Snapshots},
+			{&e.ResourcesUsage.Templates, &l.ResourcesUsage.Templates},
+			{&e.ResourcesUsage.IsoImages, &l.ResourcesUsage.IsoImages},
+			{&e.ResourcesUsage.IPAddresses, &l.ResourcesUsage.IPAddresses},
+			{&e.ResourcesUsage.LoadBalancers, &l.ResourcesUsage.LoadBalancers},
+			{&e.ResourcesUsage.PaaSServices, &l.ResourcesUsage.PaaSServices},
+		}
+		for _, product := range products {
+			product.later.UsagePerInterval = mergeUsagePerInterval(product.earlier.UsagePerInterval, product.later.UsagePerInterval, isTotal)
+		}
+		merged = l
+	case ServersUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(ServersUsage).ResourcesUsage, isTotal,
+			func(p *ServerUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case DistributedStoragesUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(DistributedStoragesUsage).ResourcesUsage, isTotal,
+			func(p *StorageUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case RocketStoragesUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(RocketStoragesUsage).ResourcesUsage, isTotal,
+			func(p *StorageUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case StorageBackupsUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(StorageBackupsUsage).ResourcesUsage, isTotal,
+			func(p *StorageBackupUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case SnapshotsUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(SnapshotsUsage).ResourcesUsage, isTotal,
+			func(p *SnapshotUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case TemplatesUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(TemplatesUsage).ResourcesUsage, isTotal,
+			func(p *TemplateUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case ISOImagesUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(ISOImagesUsage).ResourcesUsage, isTotal,
+			func(p *ISOImageUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case IPsUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(IPsUsage).ResourcesUsage, isTotal,
+			func(p *IPUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case LoadBalancersUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(LoadBalancersUsage).ResourcesUsage, isTotal,
+			func(p *LoadBalancerUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	case PaaSServicesUsage:
+		e.ResourcesUsage = mergeResourcesUsage(e.ResourcesUsage, interface{}(later).(PaaSServicesUsage).ResourcesUsage, isTotal,
+			func(p *PaaSServiceUsageProperties) (string, *[]UsagePerInterval) {
+				return p.ObjectUUID, &p.UsagePerInterval
+			})
+		merged = e
+	}
+	return merged.(T)
+}
+
+// mergeResourcesUsage merges the usage of resources of a later time range into the usage of an earlier one.
+// Resources are matched by their UUID, their usage per interval is merged and their other properties
+// are taken from the later time range.
+func mergeResourcesUsage[P any](earlier, later []P, isTotal bool, fields func(*P) (string, *[]UsagePerInterval)) []P {
+	merged := append([]P{}, earlier...)
+	indexes := make(map[string]int)
+	for i := range merged {
+		id, _ := fields(&merged[i])
+		indexes[id] = i
+	}
+	for _, properties := range later {
+		id, intervals := fields(&properties)
+		i, ok := indexes[id]
+		if !ok {
+			indexes[id] = len(merged)
+			merged = append(merged, properties)
+			continue
+		}
+		_, earlierIntervals := fields(&merged[i])
+		*intervals = mergeUsagePerInterval(*earlierIntervals, *intervals, isTotal)
+		merged[i] = properties
+	}
+	return merged
+}
+
+// mergeUsagePerInterval concatenates the usage per interval of two time ranges. Intervals with the same start,
+// or all intervals if isTotal is true, are combined by summing up the usage per product.
+func mergeUsagePerInterval(earlier, later []UsagePerInterval, isTotal bool) []UsagePerInterval {
+	var merged []UsagePerInterval
+	indexes := make(map[int64]int)
+	for _, interval := range append(append([]UsagePerInterval{}, earlier...), later...) {
+		key := interval.IntervalStart.UnixNano()
+		if isTotal {
+			key = 0
+		}
+		i, ok := indexes[key]
+		if !ok {
+			indexes[key] = len(merged)
+			interval.AccumulatedUsage = append([]Usage{}, interval.AccumulatedUsage...)
+			merged = append(merged, interval)
+			continue
+		}
+		if interval.IntervalEnd.After(merged[i].IntervalEnd.Time) {
+			merged[i].IntervalEnd = interval.IntervalEnd
+		}
+		merged[i].AccumulatedUsage = sumUsage(merged[i].AccumulatedUsage, interval.AccumulatedUsage)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].IntervalStart.Before(merged[j].IntervalStart.Time)
+	})
+	return merged
+}
+
+// sumUsage sums up two usages per product.
+func sumUsage(a, b []Usage) []Usage {
+	sum := append([]Usage{}, a...)
+	indexes := make(map[int]int)
+	for i, usage := range sum {
+		indexes[usage.ProductNumber] = i
+	}
+	for _, usage := range b {
+		if i, ok := indexes[usage.ProductNumber]; ok {
+			sum[i].Value += usage.Value
+			continue
+		}
+		indexes[usage.ProductNumber] = len(sum)
+		sum = append(sum, usage)
+	}
+	return sum
+}
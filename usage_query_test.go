@@ -0,0 +1,162 @@
+package gsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageQuery_Validate(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	type testCase struct {
+		query    UsageQuery
+		isFailed bool
+	}
+	testCases := []testCase{
+		{query: UsageQuery{Level: ProjectLevelUsage, From: from}},
+		{query: UsageQuery{Level: ContractLevelUsage, From: from, To: from.Add(time.Hour), Interval: HourIntervalVariable}},
+		{query: UsageQuery{Level: ProjectLevelUsage}, isFailed: true},
+		{query: UsageQuery{Level: 5, From: from}, isFailed: true},
+		{query: UsageQuery{Level: ProjectLevelUsage, From: from, To: from}, isFailed: true},
+		{query: UsageQuery{Level: ProjectLevelUsage, From: from, Interval: "Y"}, isFailed: true},
+		{query: UsageQuery{Level: ProjectLevelUsage, From: from, ChunkRange: -time.Hour}, isFailed: true},
+	}
+	for _, test := range testCases {
+		err := test.query.Validate()
+		if test.isFailed {
+			assert.NotNil(t, err)
+		} else {
+			assert.Nil(t, err, "Validate returned an error %v", err)
+		}
+	}
+}
+
+func TestUsageQuery_chunks(t *testing.T) {
+	query := UsageQuery{
+		From:       time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		To:         time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC),
+		Interval:   DayIntervalVariable,
+		ChunkRange: 48 * time.Hour,
+	}
+	var ranges []string
+	for _, chunk := range query.chunks() {
+		ranges = append(ranges, chunk.From.Format("02T15")+"-"+chunk.To.Format("02T15"))
+	}
+	assert.Equal(t, []string{"01T12-03T00", "03T00-05T00", "05T00-06T00"}, ranges)
+
+	query.ChunkRange = 0
+	assert.Equal(t, 1, len(query.chunks()))
+}
+
+func TestAlignToUsageInterval(t *testing.T) {
+	wednesday := time.Date(2024, 3, 6, 13, 45, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 6, 13, 0, 0, 0, time.UTC), alignToUsageInterval(wednesday, HourIntervalVariable))
+	assert.Equal(t, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), alignToUsageInterval(wednesday, DayIntervalVariable))
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), alignToUsageInterval(wednesday, WeekIntervalVariable))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), alignToUsageInterval(wednesday, MonthIntervalVariable))
+	assert.Equal(t, wednesday, alignToUsageInterval(wednesday, ""))
+}
+
+func TestGetUsage(t *testing.T) {
+	server, client, mux := setupTestClient(true)
+	defer server.Close()
+	day1 := GSTime{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	day2 := GSTime{time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}
+	day3 := GSTime{time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)}
+	otherUUID := "3a4b7f2c-1d2e-4f5a-8b9c-0d1e2f3a4b5c"
+	responses := map[string]ServersUsage{
+		day1.String(): {ResourcesUsage: []ServerUsageProperties{{
+			ObjectUUID: dummyUUID,
+			Name:       "old name",
+			UsagePerInterval: []UsagePerInterval{
+				{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 10}}},
+			},
+		}}},
+		day2.String(): {ResourcesUsage: []ServerUsageProperties{
+			{
+				ObjectUUID: dummyUUID,
+				Name:       "new name",
+				UsagePerInterval: []UsagePerInterval{
+					{IntervalStart: day2, IntervalEnd: day3, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 5}}},
+				},
+			},
+			{ObjectUUID: otherUUID, Name: "other"},
+		}},
+	}
+	var requests []string
+	interval := DayIntervalVariable
+	mux.HandleFunc(path.Join(apiContractLevelUsage, "servers"), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		query := r.URL.Query()
+		assert.Equal(t, "true", query.Get("without_deleted"))
+		assert.Equal(t, interval, query.Get("interval_variable"))
+		requests = append(requests, query.Get("from_time")+" "+query.Get("to_time"))
+		w.Header().Set(requestUUIDHeader, dummyRequestUUID)
+		res, _ := json.Marshal(responses[query.Get("from_time")])
+		fmt.Fprint(w, string(res))
+	})
+	usage, err := GetUsage[ServersUsage](emptyCtx, client, UsageQuery{
+		Level:          ContractLevelUsage,
+		From:           day1.Time,
+		To:             day3.Time,
+		WithoutDeleted: true,
+		Interval:       DayIntervalVariable,
+		ChunkRange:     24 * time.Hour,
+	})
+	assert.Nil(t, err, "GetUsage returned an error %v", err)
+	assert.Equal(t, []string{day1.String() + " " + day2.String(), day2.String() + " " + day3.String()}, requests)
+	assert.Equal(t, 2, len(usage.ResourcesUsage))
+	assert.Equal(t, "new name", usage.ResourcesUsage[0].Name)
+	assert.Equal(t, []UsagePerInterval{
+		{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 10}}},
+		{IntervalStart: day2, IntervalEnd: day3, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 5}}},
+	}, usage.ResourcesUsage[0].UsagePerInterval)
+	assert.Equal(t, otherUUID, usage.ResourcesUsage[1].ObjectUUID)
+
+	// Without interval, the usage of the chunks is summed up to a single total.
+	interval = ""
+	usage, err = GetUsage[ServersUsage](emptyCtx, client, UsageQuery{
+		Level:          ContractLevelUsage,
+		From:           day1.Time,
+		To:             day3.Time,
+		WithoutDeleted: true,
+		ChunkRange:     24 * time.Hour,
+	})
+	assert.Nil(t, err, "GetUsage returned an error %v", err)
+	assert.Equal(t, []UsagePerInterval{
+		{IntervalStart: day1, IntervalEnd: day3, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 15}}},
+	}, usage.ResourcesUsage[0].UsagePerInterval)
+
+	_, err = GetUsage[ServersUsage](emptyCtx, client, UsageQuery{Level: ContractLevelUsage})
+	assert.NotNil(t, err)
+}
+
+func TestMergeUsage(t *testing.T) {
+	day1 := GSTime{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	day2 := GSTime{time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}
+	earlier := GeneralUsage{ResourcesUsage: GeneralUsageProperties{Servers: ResourceUsageInfo{
+		CurrentUsagePerMinute: []Usage{{ProductNumber: 1, Value: 4}},
+		UsagePerInterval: []UsagePerInterval{
+			{IntervalStart: day1, IntervalEnd: GSTime{day1.Add(12 * time.Hour)}, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 3}}},
+		},
+	}}}
+	later := GeneralUsage{ResourcesUsage: GeneralUsageProperties{Servers: ResourceUsageInfo{
+		CurrentUsagePerMinute: []Usage{{ProductNumber: 1, Value: 2}},
+		UsagePerInterval: []UsagePerInterval{
+			{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 2}, {ProductNumber: 2, Value: 1}}},
+		},
+	}}}
+	merged := mergeUsage(earlier, later, false)
+	assert.Equal(t, []Usage{{ProductNumber: 1, Value: 2}}, merged.ResourcesUsage.Servers.CurrentUsagePerMinute)
+	assert.Equal(t, []UsagePerInterval{
+		{IntervalStart: day1, IntervalEnd: day2, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 5}, {ProductNumber: 2, Value: 1}}},
+	}, merged.ResourcesUsage.Servers.UsagePerInterval)
+	assert.Equal(t, []UsagePerInterval{
+		{IntervalStart: day1, IntervalEnd: GSTime{day1.Add(12 * time.Hour)}, AccumulatedUsage: []Usage{{ProductNumber: 1, Value: 3}}},
+	}, earlier.ResourcesUsage.Servers.UsagePerInterval)
+}